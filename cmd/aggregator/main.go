@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"github.com/Pavel26ru/aggregator-service/internal/config"
 	"github.com/Pavel26ru/aggregator-service/internal/logging"
 	"github.com/Pavel26ru/aggregator-service/internal/shutdown"
+	"github.com/Pavel26ru/aggregator-service/internal/tracing"
 )
 
 func main() {
@@ -38,7 +40,15 @@ func Run(ctx context.Context) error {
 
 	sh := shutdown.New(logger)
 
-	application := app.New(ctx, logger, cfg)
+	// Хуки выполняются в обратном порядке: спаны экспортируются
+	// после остановки остальных компонентов.
+	tracerProvider, shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	sh.Register(shutdownTracing)
+
+	application := app.New(ctx, logger, cfg, tracerProvider)
 
 	sh.Register(application.Stop)
 
@@ -48,13 +58,18 @@ func Run(ctx context.Context) error {
 		slog.String("gRPC", cfg.GRPC.Port),
 	)
 
-	<-ctx.Done()
-	logger.Info("received shutdown signal")
+	var runErr error
+	select {
+	case <-ctx.Done():
+		logger.Info("received shutdown signal")
+	case runErr = <-application.Err():
+		logger.Error("application failed", slog.Any("error", runErr))
+	}
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
 	sh.Shutdown(shutdownCtx)
 
-	return nil
+	return runErr
 }
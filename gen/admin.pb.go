@@ -0,0 +1,838 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: api/proto/admin.proto
+
+package aggrpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetConsumerGroupStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConsumerGroupStatusRequest) Reset() {
+	*x = GetConsumerGroupStatusRequest{}
+	mi := &file_api_proto_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConsumerGroupStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConsumerGroupStatusRequest) ProtoMessage() {}
+
+func (x *GetConsumerGroupStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConsumerGroupStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetConsumerGroupStatusRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_admin_proto_rawDescGZIP(), []int{0}
+}
+
+type TopicAssignment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topic         string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Partitions    []int32                `protobuf:"varint,2,rep,packed,name=partitions,proto3" json:"partitions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicAssignment) Reset() {
+	*x = TopicAssignment{}
+	mi := &file_api_proto_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicAssignment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicAssignment) ProtoMessage() {}
+
+func (x *TopicAssignment) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicAssignment.ProtoReflect.Descriptor instead.
+func (*TopicAssignment) Descriptor() ([]byte, []int) {
+	return file_api_proto_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TopicAssignment) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *TopicAssignment) GetPartitions() []int32 {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+type GroupMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MemberId      string                 `protobuf:"bytes,1,opt,name=member_id,json=memberId,proto3" json:"member_id,omitempty"`
+	InstanceId    string                 `protobuf:"bytes,2,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+	ClientId      string                 `protobuf:"bytes,3,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ClientHost    string                 `protobuf:"bytes,4,opt,name=client_host,json=clientHost,proto3" json:"client_host,omitempty"`
+	Assignments   []*TopicAssignment     `protobuf:"bytes,5,rep,name=assignments,proto3" json:"assignments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupMember) Reset() {
+	*x = GroupMember{}
+	mi := &file_api_proto_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupMember) ProtoMessage() {}
+
+func (x *GroupMember) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupMember.ProtoReflect.Descriptor instead.
+func (*GroupMember) Descriptor() ([]byte, []int) {
+	return file_api_proto_admin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GroupMember) GetMemberId() string {
+	if x != nil {
+		return x.MemberId
+	}
+	return ""
+}
+
+func (x *GroupMember) GetInstanceId() string {
+	if x != nil {
+		return x.InstanceId
+	}
+	return ""
+}
+
+func (x *GroupMember) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *GroupMember) GetClientHost() string {
+	if x != nil {
+		return x.ClientHost
+	}
+	return ""
+}
+
+func (x *GroupMember) GetAssignments() []*TopicAssignment {
+	if x != nil {
+		return x.Assignments
+	}
+	return nil
+}
+
+type PartitionLag struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Topic           string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Partition       int32                  `protobuf:"varint,2,opt,name=partition,proto3" json:"partition,omitempty"`
+	CommittedOffset int64                  `protobuf:"varint,3,opt,name=committed_offset,json=committedOffset,proto3" json:"committed_offset,omitempty"`
+	HighWatermark   int64                  `protobuf:"varint,4,opt,name=high_watermark,json=highWatermark,proto3" json:"high_watermark,omitempty"`
+	Lag             int64                  `protobuf:"varint,5,opt,name=lag,proto3" json:"lag,omitempty"`
+	MemberId        string                 `protobuf:"bytes,6,opt,name=member_id,json=memberId,proto3" json:"member_id,omitempty"`
+	Error           string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PartitionLag) Reset() {
+	*x = PartitionLag{}
+	mi := &file_api_proto_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PartitionLag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartitionLag) ProtoMessage() {}
+
+func (x *PartitionLag) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartitionLag.ProtoReflect.Descriptor instead.
+func (*PartitionLag) Descriptor() ([]byte, []int) {
+	return file_api_proto_admin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PartitionLag) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *PartitionLag) GetPartition() int32 {
+	if x != nil {
+		return x.Partition
+	}
+	return 0
+}
+
+func (x *PartitionLag) GetCommittedOffset() int64 {
+	if x != nil {
+		return x.CommittedOffset
+	}
+	return 0
+}
+
+func (x *PartitionLag) GetHighWatermark() int64 {
+	if x != nil {
+		return x.HighWatermark
+	}
+	return 0
+}
+
+func (x *PartitionLag) GetLag() int64 {
+	if x != nil {
+		return x.Lag
+	}
+	return 0
+}
+
+func (x *PartitionLag) GetMemberId() string {
+	if x != nil {
+		return x.MemberId
+	}
+	return ""
+}
+
+func (x *PartitionLag) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ConsumerGroupStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Protocol      string                 `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Members       []*GroupMember         `protobuf:"bytes,4,rep,name=members,proto3" json:"members,omitempty"`
+	Partitions    []*PartitionLag        `protobuf:"bytes,5,rep,name=partitions,proto3" json:"partitions,omitempty"`
+	TotalLag      int64                  `protobuf:"varint,6,opt,name=total_lag,json=totalLag,proto3" json:"total_lag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsumerGroupStatus) Reset() {
+	*x = ConsumerGroupStatus{}
+	mi := &file_api_proto_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsumerGroupStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumerGroupStatus) ProtoMessage() {}
+
+func (x *ConsumerGroupStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumerGroupStatus.ProtoReflect.Descriptor instead.
+func (*ConsumerGroupStatus) Descriptor() ([]byte, []int) {
+	return file_api_proto_admin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ConsumerGroupStatus) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *ConsumerGroupStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ConsumerGroupStatus) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ConsumerGroupStatus) GetMembers() []*GroupMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *ConsumerGroupStatus) GetPartitions() []*PartitionLag {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+func (x *ConsumerGroupStatus) GetTotalLag() int64 {
+	if x != nil {
+		return x.TotalLag
+	}
+	return 0
+}
+
+// Задаётся либо from_timestamp, либо offsets (партиция -> офсет).
+// topic — входной топик из привязок; пусто — единственный явный топик.
+type StartReplayRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromTimestamp *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from_timestamp,json=fromTimestamp,proto3" json:"from_timestamp,omitempty"`
+	Offsets       map[int32]int64        `protobuf:"bytes,2,rep,name=offsets,proto3" json:"offsets,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Topic         string                 `protobuf:"bytes,3,opt,name=topic,proto3" json:"topic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartReplayRequest) Reset() {
+	*x = StartReplayRequest{}
+	mi := &file_api_proto_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartReplayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartReplayRequest) ProtoMessage() {}
+
+func (x *StartReplayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartReplayRequest.ProtoReflect.Descriptor instead.
+func (*StartReplayRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_admin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StartReplayRequest) GetFromTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FromTimestamp
+	}
+	return nil
+}
+
+func (x *StartReplayRequest) GetOffsets() map[int32]int64 {
+	if x != nil {
+		return x.Offsets
+	}
+	return nil
+}
+
+func (x *StartReplayRequest) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+type GetReplayRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReplayRequest) Reset() {
+	*x = GetReplayRequest{}
+	mi := &file_api_proto_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReplayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReplayRequest) ProtoMessage() {}
+
+func (x *GetReplayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReplayRequest.ProtoReflect.Descriptor instead.
+func (*GetReplayRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_admin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetReplayRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CancelReplayRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelReplayRequest) Reset() {
+	*x = CancelReplayRequest{}
+	mi := &file_api_proto_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelReplayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelReplayRequest) ProtoMessage() {}
+
+func (x *CancelReplayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelReplayRequest.ProtoReflect.Descriptor instead.
+func (*CancelReplayRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CancelReplayRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ReplayPartitionProgress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Partition     int32                  `protobuf:"varint,1,opt,name=partition,proto3" json:"partition,omitempty"`
+	StartOffset   int64                  `protobuf:"varint,2,opt,name=start_offset,json=startOffset,proto3" json:"start_offset,omitempty"`
+	CurrentOffset int64                  `protobuf:"varint,3,opt,name=current_offset,json=currentOffset,proto3" json:"current_offset,omitempty"`
+	EndOffset     int64                  `protobuf:"varint,4,opt,name=end_offset,json=endOffset,proto3" json:"end_offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayPartitionProgress) Reset() {
+	*x = ReplayPartitionProgress{}
+	mi := &file_api_proto_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayPartitionProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayPartitionProgress) ProtoMessage() {}
+
+func (x *ReplayPartitionProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayPartitionProgress.ProtoReflect.Descriptor instead.
+func (*ReplayPartitionProgress) Descriptor() ([]byte, []int) {
+	return file_api_proto_admin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ReplayPartitionProgress) GetPartition() int32 {
+	if x != nil {
+		return x.Partition
+	}
+	return 0
+}
+
+func (x *ReplayPartitionProgress) GetStartOffset() int64 {
+	if x != nil {
+		return x.StartOffset
+	}
+	return 0
+}
+
+func (x *ReplayPartitionProgress) GetCurrentOffset() int64 {
+	if x != nil {
+		return x.CurrentOffset
+	}
+	return 0
+}
+
+func (x *ReplayPartitionProgress) GetEndOffset() int64 {
+	if x != nil {
+		return x.EndOffset
+	}
+	return 0
+}
+
+type ReplayJob struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Id            string                     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	State         string                     `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Partitions    []*ReplayPartitionProgress `protobuf:"bytes,3,rep,name=partitions,proto3" json:"partitions,omitempty"`
+	Processed     int64                      `protobuf:"varint,4,opt,name=processed,proto3" json:"processed,omitempty"`
+	Failed        int64                      `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`
+	StartedAt     *timestamppb.Timestamp     `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp     `protobuf:"bytes,7,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Error         string                     `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	Topic         string                     `protobuf:"bytes,9,opt,name=topic,proto3" json:"topic,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayJob) Reset() {
+	*x = ReplayJob{}
+	mi := &file_api_proto_admin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayJob) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayJob) ProtoMessage() {}
+
+func (x *ReplayJob) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_admin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayJob.ProtoReflect.Descriptor instead.
+func (*ReplayJob) Descriptor() ([]byte, []int) {
+	return file_api_proto_admin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ReplayJob) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReplayJob) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ReplayJob) GetPartitions() []*ReplayPartitionProgress {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+func (x *ReplayJob) GetProcessed() int64 {
+	if x != nil {
+		return x.Processed
+	}
+	return 0
+}
+
+func (x *ReplayJob) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ReplayJob) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ReplayJob) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *ReplayJob) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ReplayJob) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+var File_api_proto_admin_proto protoreflect.FileDescriptor
+
+const file_api_proto_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x15api/proto/admin.proto\x12\n" +
+	"aggregator\x1a\x1fgoogle/protobuf/timestamp.proto\"\x1f\n" +
+	"\x1dGetConsumerGroupStatusRequest\"G\n" +
+	"\x0fTopicAssignment\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x1e\n" +
+	"\n" +
+	"partitions\x18\x02 \x03(\x05R\n" +
+	"partitions\"\xc8\x01\n" +
+	"\vGroupMember\x12\x1b\n" +
+	"\tmember_id\x18\x01 \x01(\tR\bmemberId\x12\x1f\n" +
+	"\vinstance_id\x18\x02 \x01(\tR\n" +
+	"instanceId\x12\x1b\n" +
+	"\tclient_id\x18\x03 \x01(\tR\bclientId\x12\x1f\n" +
+	"\vclient_host\x18\x04 \x01(\tR\n" +
+	"clientHost\x12=\n" +
+	"\vassignments\x18\x05 \x03(\v2\x1b.aggregator.TopicAssignmentR\vassignments\"\xd9\x01\n" +
+	"\fPartitionLag\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x1c\n" +
+	"\tpartition\x18\x02 \x01(\x05R\tpartition\x12)\n" +
+	"\x10committed_offset\x18\x03 \x01(\x03R\x0fcommittedOffset\x12%\n" +
+	"\x0ehigh_watermark\x18\x04 \x01(\x03R\rhighWatermark\x12\x10\n" +
+	"\x03lag\x18\x05 \x01(\x03R\x03lag\x12\x1b\n" +
+	"\tmember_id\x18\x06 \x01(\tR\bmemberId\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\xe7\x01\n" +
+	"\x13ConsumerGroupStatus\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12\x1a\n" +
+	"\bprotocol\x18\x03 \x01(\tR\bprotocol\x121\n" +
+	"\amembers\x18\x04 \x03(\v2\x17.aggregator.GroupMemberR\amembers\x128\n" +
+	"\n" +
+	"partitions\x18\x05 \x03(\v2\x18.aggregator.PartitionLagR\n" +
+	"partitions\x12\x1b\n" +
+	"\ttotal_lag\x18\x06 \x01(\x03R\btotalLag\"\xf0\x01\n" +
+	"\x12StartReplayRequest\x12A\n" +
+	"\x0efrom_timestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\rfromTimestamp\x12E\n" +
+	"\aoffsets\x18\x02 \x03(\v2+.aggregator.StartReplayRequest.OffsetsEntryR\aoffsets\x12\x14\n" +
+	"\x05topic\x18\x03 \x01(\tR\x05topic\x1a:\n" +
+	"\fOffsetsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\"\n" +
+	"\x10GetReplayRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"%\n" +
+	"\x13CancelReplayRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xa0\x01\n" +
+	"\x17ReplayPartitionProgress\x12\x1c\n" +
+	"\tpartition\x18\x01 \x01(\x05R\tpartition\x12!\n" +
+	"\fstart_offset\x18\x02 \x01(\x03R\vstartOffset\x12%\n" +
+	"\x0ecurrent_offset\x18\x03 \x01(\x03R\rcurrentOffset\x12\x1d\n" +
+	"\n" +
+	"end_offset\x18\x04 \x01(\x03R\tendOffset\"\xd0\x02\n" +
+	"\tReplayJob\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12C\n" +
+	"\n" +
+	"partitions\x18\x03 \x03(\v2#.aggregator.ReplayPartitionProgressR\n" +
+	"partitions\x12\x1c\n" +
+	"\tprocessed\x18\x04 \x01(\x03R\tprocessed\x12\x16\n" +
+	"\x06failed\x18\x05 \x01(\x03R\x06failed\x129\n" +
+	"\n" +
+	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\x12\x14\n" +
+	"\x05topic\x18\t \x01(\tR\x05topic2\xc4\x02\n" +
+	"\fAdminService\x12d\n" +
+	"\x16GetConsumerGroupStatus\x12).aggregator.GetConsumerGroupStatusRequest\x1a\x1f.aggregator.ConsumerGroupStatus\x12D\n" +
+	"\vStartReplay\x12\x1e.aggregator.StartReplayRequest\x1a\x15.aggregator.ReplayJob\x12@\n" +
+	"\tGetReplay\x12\x1c.aggregator.GetReplayRequest\x1a\x15.aggregator.ReplayJob\x12F\n" +
+	"\fCancelReplay\x12\x1f.aggregator.CancelReplayRequest\x1a\x15.aggregator.ReplayJobB:Z8github.com/Pavel26ru/aggregator-service/api/proto/aggrpbb\x06proto3"
+
+var (
+	file_api_proto_admin_proto_rawDescOnce sync.Once
+	file_api_proto_admin_proto_rawDescData []byte
+)
+
+func file_api_proto_admin_proto_rawDescGZIP() []byte {
+	file_api_proto_admin_proto_rawDescOnce.Do(func() {
+		file_api_proto_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_admin_proto_rawDesc), len(file_api_proto_admin_proto_rawDesc)))
+	})
+	return file_api_proto_admin_proto_rawDescData
+}
+
+var file_api_proto_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_api_proto_admin_proto_goTypes = []any{
+	(*GetConsumerGroupStatusRequest)(nil), // 0: aggregator.GetConsumerGroupStatusRequest
+	(*TopicAssignment)(nil),               // 1: aggregator.TopicAssignment
+	(*GroupMember)(nil),                   // 2: aggregator.GroupMember
+	(*PartitionLag)(nil),                  // 3: aggregator.PartitionLag
+	(*ConsumerGroupStatus)(nil),           // 4: aggregator.ConsumerGroupStatus
+	(*StartReplayRequest)(nil),            // 5: aggregator.StartReplayRequest
+	(*GetReplayRequest)(nil),              // 6: aggregator.GetReplayRequest
+	(*CancelReplayRequest)(nil),           // 7: aggregator.CancelReplayRequest
+	(*ReplayPartitionProgress)(nil),       // 8: aggregator.ReplayPartitionProgress
+	(*ReplayJob)(nil),                     // 9: aggregator.ReplayJob
+	nil,                                   // 10: aggregator.StartReplayRequest.OffsetsEntry
+	(*timestamppb.Timestamp)(nil),         // 11: google.protobuf.Timestamp
+}
+var file_api_proto_admin_proto_depIdxs = []int32{
+	1,  // 0: aggregator.GroupMember.assignments:type_name -> aggregator.TopicAssignment
+	2,  // 1: aggregator.ConsumerGroupStatus.members:type_name -> aggregator.GroupMember
+	3,  // 2: aggregator.ConsumerGroupStatus.partitions:type_name -> aggregator.PartitionLag
+	11, // 3: aggregator.StartReplayRequest.from_timestamp:type_name -> google.protobuf.Timestamp
+	10, // 4: aggregator.StartReplayRequest.offsets:type_name -> aggregator.StartReplayRequest.OffsetsEntry
+	8,  // 5: aggregator.ReplayJob.partitions:type_name -> aggregator.ReplayPartitionProgress
+	11, // 6: aggregator.ReplayJob.started_at:type_name -> google.protobuf.Timestamp
+	11, // 7: aggregator.ReplayJob.finished_at:type_name -> google.protobuf.Timestamp
+	0,  // 8: aggregator.AdminService.GetConsumerGroupStatus:input_type -> aggregator.GetConsumerGroupStatusRequest
+	5,  // 9: aggregator.AdminService.StartReplay:input_type -> aggregator.StartReplayRequest
+	6,  // 10: aggregator.AdminService.GetReplay:input_type -> aggregator.GetReplayRequest
+	7,  // 11: aggregator.AdminService.CancelReplay:input_type -> aggregator.CancelReplayRequest
+	4,  // 12: aggregator.AdminService.GetConsumerGroupStatus:output_type -> aggregator.ConsumerGroupStatus
+	9,  // 13: aggregator.AdminService.StartReplay:output_type -> aggregator.ReplayJob
+	9,  // 14: aggregator.AdminService.GetReplay:output_type -> aggregator.ReplayJob
+	9,  // 15: aggregator.AdminService.CancelReplay:output_type -> aggregator.ReplayJob
+	12, // [12:16] is the sub-list for method output_type
+	8,  // [8:12] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_api_proto_admin_proto_init() }
+func file_api_proto_admin_proto_init() {
+	if File_api_proto_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_admin_proto_rawDesc), len(file_api_proto_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_proto_admin_proto_goTypes,
+		DependencyIndexes: file_api_proto_admin_proto_depIdxs,
+		MessageInfos:      file_api_proto_admin_proto_msgTypes,
+	}.Build()
+	File_api_proto_admin_proto = out.File
+	file_api_proto_admin_proto_goTypes = nil
+	file_api_proto_admin_proto_depIdxs = nil
+}
@@ -27,6 +27,7 @@ type GetMaxRequest struct {
 	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
 	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
 	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -82,6 +83,13 @@ func (x *GetMaxRequest) GetTo() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *GetMaxRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
 type MaxValue struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
@@ -186,24 +194,201 @@ func (x *GetMaxResponse) GetRecords() []*MaxValue {
 	return nil
 }
 
+type ListRevisionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRevisionsRequest) Reset() {
+	*x = ListRevisionsRequest{}
+	mi := &file_api_proto_aggregator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRevisionsRequest) ProtoMessage() {}
+
+func (x *ListRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_aggregator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*ListRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_aggregator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListRevisionsRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+type Revision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Version       int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Ts            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=ts,proto3" json:"ts,omitempty"`
+	MaxValue      int64                  `protobuf:"varint,4,opt,name=max_value,json=maxValue,proto3" json:"max_value,omitempty"`
+	RecordedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=recorded_at,json=recordedAt,proto3" json:"recorded_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Revision) Reset() {
+	*x = Revision{}
+	mi := &file_api_proto_aggregator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Revision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Revision) ProtoMessage() {}
+
+func (x *Revision) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_aggregator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Revision.ProtoReflect.Descriptor instead.
+func (*Revision) Descriptor() ([]byte, []int) {
+	return file_api_proto_aggregator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Revision) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Revision) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Revision) GetTs() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Ts
+	}
+	return nil
+}
+
+func (x *Revision) GetMaxValue() int64 {
+	if x != nil {
+		return x.MaxValue
+	}
+	return 0
+}
+
+func (x *Revision) GetRecordedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RecordedAt
+	}
+	return nil
+}
+
+type ListRevisionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revisions     []*Revision            `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRevisionsResponse) Reset() {
+	*x = ListRevisionsResponse{}
+	mi := &file_api_proto_aggregator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRevisionsResponse) ProtoMessage() {}
+
+func (x *ListRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_aggregator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*ListRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_aggregator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListRevisionsResponse) GetRevisions() []*Revision {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
 var File_api_proto_aggregator_proto protoreflect.FileDescriptor
 
 const file_api_proto_aggregator_proto_rawDesc = "" +
 	"\n" +
 	"\x1aapi/proto/aggregator.proto\x12\n" +
-	"aggregator\x1a\x1fgoogle/protobuf/timestamp.proto\"\x7f\n" +
+	"aggregator\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb0\x01\n" +
 	"\rGetMaxRequest\x12\x12\n" +
 	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12.\n" +
 	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
-	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"g\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12/\n" +
+	"\x05as_of\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"g\n" +
 	"\bMaxValue\x12\x12\n" +
 	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12*\n" +
 	"\x02ts\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02ts\x12\x1b\n" +
 	"\tmax_value\x18\x03 \x01(\x03R\bmaxValue\"@\n" +
 	"\x0eGetMaxResponse\x12.\n" +
-	"\arecords\x18\x01 \x03(\v2\x14.aggregator.MaxValueR\arecords2T\n" +
+	"\arecords\x18\x01 \x03(\v2\x14.aggregator.MaxValueR\arecords\"*\n" +
+	"\x14ListRevisionsRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\"\xbe\x01\n" +
+	"\bRevision\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\x12*\n" +
+	"\x02ts\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02ts\x12\x1b\n" +
+	"\tmax_value\x18\x04 \x01(\x03R\bmaxValue\x12;\n" +
+	"\vrecorded_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"recordedAt\"K\n" +
+	"\x15ListRevisionsResponse\x122\n" +
+	"\trevisions\x18\x01 \x03(\v2\x14.aggregator.RevisionR\trevisions2\xaa\x01\n" +
 	"\x11AggregatorService\x12?\n" +
-	"\x06GetMax\x12\x19.aggregator.GetMaxRequest\x1a\x1a.aggregator.GetMaxResponseB:Z8github.com/Pavel26ru/aggregator-service/api/proto/aggrpbb\x06proto3"
+	"\x06GetMax\x12\x19.aggregator.GetMaxRequest\x1a\x1a.aggregator.GetMaxResponse\x12T\n" +
+	"\rListRevisions\x12 .aggregator.ListRevisionsRequest\x1a!.aggregator.ListRevisionsResponseB:Z8github.com/Pavel26ru/aggregator-service/api/proto/aggrpbb\x06proto3"
 
 var (
 	file_api_proto_aggregator_proto_rawDescOnce sync.Once
@@ -217,25 +402,34 @@ func file_api_proto_aggregator_proto_rawDescGZIP() []byte {
 	return file_api_proto_aggregator_proto_rawDescData
 }
 
-var file_api_proto_aggregator_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_api_proto_aggregator_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_api_proto_aggregator_proto_goTypes = []any{
 	(*GetMaxRequest)(nil),         // 0: aggregator.GetMaxRequest
 	(*MaxValue)(nil),              // 1: aggregator.MaxValue
 	(*GetMaxResponse)(nil),        // 2: aggregator.GetMaxResponse
-	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+	(*ListRevisionsRequest)(nil),  // 3: aggregator.ListRevisionsRequest
+	(*Revision)(nil),              // 4: aggregator.Revision
+	(*ListRevisionsResponse)(nil), // 5: aggregator.ListRevisionsResponse
+	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
 }
 var file_api_proto_aggregator_proto_depIdxs = []int32{
-	3, // 0: aggregator.GetMaxRequest.from:type_name -> google.protobuf.Timestamp
-	3, // 1: aggregator.GetMaxRequest.to:type_name -> google.protobuf.Timestamp
-	3, // 2: aggregator.MaxValue.ts:type_name -> google.protobuf.Timestamp
-	1, // 3: aggregator.GetMaxResponse.records:type_name -> aggregator.MaxValue
-	0, // 4: aggregator.AggregatorService.GetMax:input_type -> aggregator.GetMaxRequest
-	2, // 5: aggregator.AggregatorService.GetMax:output_type -> aggregator.GetMaxResponse
-	5, // [5:6] is the sub-list for method output_type
-	4, // [4:5] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	6,  // 0: aggregator.GetMaxRequest.from:type_name -> google.protobuf.Timestamp
+	6,  // 1: aggregator.GetMaxRequest.to:type_name -> google.protobuf.Timestamp
+	6,  // 2: aggregator.GetMaxRequest.as_of:type_name -> google.protobuf.Timestamp
+	6,  // 3: aggregator.MaxValue.ts:type_name -> google.protobuf.Timestamp
+	1,  // 4: aggregator.GetMaxResponse.records:type_name -> aggregator.MaxValue
+	6,  // 5: aggregator.Revision.ts:type_name -> google.protobuf.Timestamp
+	6,  // 6: aggregator.Revision.recorded_at:type_name -> google.protobuf.Timestamp
+	4,  // 7: aggregator.ListRevisionsResponse.revisions:type_name -> aggregator.Revision
+	0,  // 8: aggregator.AggregatorService.GetMax:input_type -> aggregator.GetMaxRequest
+	3,  // 9: aggregator.AggregatorService.ListRevisions:input_type -> aggregator.ListRevisionsRequest
+	2,  // 10: aggregator.AggregatorService.GetMax:output_type -> aggregator.GetMaxResponse
+	5,  // 11: aggregator.AggregatorService.ListRevisions:output_type -> aggregator.ListRevisionsResponse
+	10, // [10:12] is the sub-list for method output_type
+	8,  // [8:10] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_aggregator_proto_init() }
@@ -249,7 +443,7 @@ func file_api_proto_aggregator_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_aggregator_proto_rawDesc), len(file_api_proto_aggregator_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,8 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AggregatorService_GetMax_FullMethodName = "/aggregator.AggregatorService/GetMax"
+	AggregatorService_GetMax_FullMethodName        = "/aggregator.AggregatorService/GetMax"
+	AggregatorService_ListRevisions_FullMethodName = "/aggregator.AggregatorService/ListRevisions"
 )
 
 // AggregatorServiceClient is the client API for AggregatorService service.
@@ -27,6 +28,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AggregatorServiceClient interface {
 	GetMax(ctx context.Context, in *GetMaxRequest, opts ...grpc.CallOption) (*GetMaxResponse, error)
+	ListRevisions(ctx context.Context, in *ListRevisionsRequest, opts ...grpc.CallOption) (*ListRevisionsResponse, error)
 }
 
 type aggregatorServiceClient struct {
@@ -47,11 +49,22 @@ func (c *aggregatorServiceClient) GetMax(ctx context.Context, in *GetMaxRequest,
 	return out, nil
 }
 
+func (c *aggregatorServiceClient) ListRevisions(ctx context.Context, in *ListRevisionsRequest, opts ...grpc.CallOption) (*ListRevisionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRevisionsResponse)
+	err := c.cc.Invoke(ctx, AggregatorService_ListRevisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AggregatorServiceServer is the server API for AggregatorService service.
 // All implementations must embed UnimplementedAggregatorServiceServer
 // for forward compatibility.
 type AggregatorServiceServer interface {
 	GetMax(context.Context, *GetMaxRequest) (*GetMaxResponse, error)
+	ListRevisions(context.Context, *ListRevisionsRequest) (*ListRevisionsResponse, error)
 	mustEmbedUnimplementedAggregatorServiceServer()
 }
 
@@ -65,6 +78,9 @@ type UnimplementedAggregatorServiceServer struct{}
 func (UnimplementedAggregatorServiceServer) GetMax(context.Context, *GetMaxRequest) (*GetMaxResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMax not implemented")
 }
+func (UnimplementedAggregatorServiceServer) ListRevisions(context.Context, *ListRevisionsRequest) (*ListRevisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRevisions not implemented")
+}
 func (UnimplementedAggregatorServiceServer) mustEmbedUnimplementedAggregatorServiceServer() {}
 func (UnimplementedAggregatorServiceServer) testEmbeddedByValue()                           {}
 
@@ -104,6 +120,24 @@ func _AggregatorService_GetMax_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AggregatorService_ListRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AggregatorServiceServer).ListRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AggregatorService_ListRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AggregatorServiceServer).ListRevisions(ctx, req.(*ListRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AggregatorService_ServiceDesc is the grpc.ServiceDesc for AggregatorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,6 +149,10 @@ var AggregatorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMax",
 			Handler:    _AggregatorService_GetMax_Handler,
 		},
+		{
+			MethodName: "ListRevisions",
+			Handler:    _AggregatorService_ListRevisions_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/aggregator.proto",
@@ -0,0 +1,146 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: api/proto/value_record.proto
+
+package aggrpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ValueRecord — сообщение во входном топике Kafka
+// (content-type: application/vnd.aggregator.value-record.v1+protobuf).
+type ValueRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Value         []int64                `protobuf:"varint,3,rep,packed,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValueRecord) Reset() {
+	*x = ValueRecord{}
+	mi := &file_api_proto_value_record_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValueRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValueRecord) ProtoMessage() {}
+
+func (x *ValueRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_value_record_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValueRecord.ProtoReflect.Descriptor instead.
+func (*ValueRecord) Descriptor() ([]byte, []int) {
+	return file_api_proto_value_record_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ValueRecord) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *ValueRecord) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *ValueRecord) GetValue() []int64 {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+var File_api_proto_value_record_proto protoreflect.FileDescriptor
+
+const file_api_proto_value_record_proto_rawDesc = "" +
+	"\n" +
+	"\x1capi/proto/value_record.proto\x12\n" +
+	"aggregator\x1a\x1fgoogle/protobuf/timestamp.proto\"q\n" +
+	"\vValueRecord\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x14\n" +
+	"\x05value\x18\x03 \x03(\x03R\x05valueB:Z8github.com/Pavel26ru/aggregator-service/api/proto/aggrpbb\x06proto3"
+
+var (
+	file_api_proto_value_record_proto_rawDescOnce sync.Once
+	file_api_proto_value_record_proto_rawDescData []byte
+)
+
+func file_api_proto_value_record_proto_rawDescGZIP() []byte {
+	file_api_proto_value_record_proto_rawDescOnce.Do(func() {
+		file_api_proto_value_record_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_proto_value_record_proto_rawDesc), len(file_api_proto_value_record_proto_rawDesc)))
+	})
+	return file_api_proto_value_record_proto_rawDescData
+}
+
+var file_api_proto_value_record_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_api_proto_value_record_proto_goTypes = []any{
+	(*ValueRecord)(nil),           // 0: aggregator.ValueRecord
+	(*timestamppb.Timestamp)(nil), // 1: google.protobuf.Timestamp
+}
+var file_api_proto_value_record_proto_depIdxs = []int32{
+	1, // 0: aggregator.ValueRecord.timestamp:type_name -> google.protobuf.Timestamp
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_api_proto_value_record_proto_init() }
+func file_api_proto_value_record_proto_init() {
+	if File_api_proto_value_record_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_value_record_proto_rawDesc), len(file_api_proto_value_record_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_api_proto_value_record_proto_goTypes,
+		DependencyIndexes: file_api_proto_value_record_proto_depIdxs,
+		MessageInfos:      file_api_proto_value_record_proto_msgTypes,
+	}.Build()
+	File_api_proto_value_record_proto = out.File
+	file_api_proto_value_record_proto_goTypes = nil
+	file_api_proto_value_record_proto_depIdxs = nil
+}
@@ -0,0 +1,186 @@
+// Package apierr сопоставляет ошибки сервиса и репозитория со стабильными кодами,
+// которые клиенты получают и по REST (problem+json), и по gRPC (ErrorInfo).
+package apierr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+)
+
+// Domain — домен ошибок в errdetails.ErrorInfo.
+const Domain = "aggregator-service"
+
+// Code — стабильный код ошибки. Клиенты могут на него полагаться,
+// в отличие от текста сообщения.
+type Code string
+
+const (
+	CodeInvalidArgument  Code = "invalid_argument"
+	CodeUnauthenticated  Code = "unauthenticated"
+	CodePermissionDenied Code = "permission_denied"
+	CodeNotFound         Code = "not_found"
+	CodeRecordNotFound   Code = "record_not_found"
+	CodeReplayNotFound   Code = "replay_not_found"
+	CodeTooManyReplays   Code = "too_many_replays"
+	CodeRateLimited      Code = "rate_limited"
+	CodeCanceled         Code = "canceled"
+	CodeDeadlineExceeded Code = "deadline_exceeded"
+	CodeUnavailable      Code = "unavailable"
+	CodeInternal         Code = "internal"
+)
+
+// StatusClientClosedRequest — нестандартный статус 499: клиент закрыл
+// соединение, не дождавшись ответа.
+const StatusClientClosedRequest = 499
+
+// HTTPStatus — статус ответа REST для кода.
+func (c Code) HTTPStatus() int {
+	switch c {
+	case CodeInvalidArgument:
+		return http.StatusBadRequest
+	case CodeUnauthenticated:
+		return http.StatusUnauthorized
+	case CodePermissionDenied:
+		return http.StatusForbidden
+	case CodeNotFound, CodeRecordNotFound, CodeReplayNotFound:
+		return http.StatusNotFound
+	case CodeRateLimited, CodeTooManyReplays:
+		return http.StatusTooManyRequests
+	case CodeCanceled:
+		return StatusClientClosedRequest
+	case CodeDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case CodeUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GRPCCode — код статуса gRPC для кода.
+func (c Code) GRPCCode() codes.Code {
+	switch c {
+	case CodeInvalidArgument:
+		return codes.InvalidArgument
+	case CodeUnauthenticated:
+		return codes.Unauthenticated
+	case CodePermissionDenied:
+		return codes.PermissionDenied
+	case CodeNotFound, CodeRecordNotFound, CodeReplayNotFound:
+		return codes.NotFound
+	case CodeRateLimited, CodeTooManyReplays:
+		return codes.ResourceExhausted
+	case CodeCanceled:
+		return codes.Canceled
+	case CodeDeadlineExceeded:
+		return codes.DeadlineExceeded
+	case CodeUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// Reason — код в виде UPPER_SNAKE_CASE для errdetails.ErrorInfo.
+func (c Code) Reason() string {
+	return strings.ToUpper(string(c))
+}
+
+// FieldViolation описывает ошибку в конкретном параметре или поле тела запроса.
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// Error — ошибка, готовая к отдаче клиенту: код, безопасное сообщение
+// и нарушения по полям.
+type Error struct {
+	Code    Code
+	Message string
+	Fields  []FieldViolation
+	cause   error
+}
+
+func New(code Code, message string, fields ...FieldViolation) *Error {
+	return &Error{Code: code, Message: message, Fields: fields}
+}
+
+// Wrap сохраняет причину для логов; клиент видит только message.
+func Wrap(code Code, message string, cause error) *Error {
+	return &Error{Code: code, Message: message, cause: cause}
+}
+
+// InvalidArgument — ошибка в параметре field.
+func InvalidArgument(field, description string) *Error {
+	return New(CodeInvalidArgument, "invalid parameter '"+field+"'", FieldViolation{Field: field, Description: description})
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Internal сообщает, что ошибка не вызвана запросом клиента и её нужно
+// залогировать с исходной причиной.
+func (e *Error) Internal() bool {
+	switch e.Code {
+	case CodeInternal, CodeUnavailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// Interrupted сообщает, что запрос прерван отменой или истёкшим сроком.
+// Это не сбой сервиса, но причину стоит залогировать с предупреждением.
+func (e *Error) Interrupted() bool {
+	return e.Code == CodeCanceled || e.Code == CodeDeadlineExceeded
+}
+
+// From сопоставляет ошибку сервиса или репозитория с кодом. Неизвестные ошибки
+// становятся CodeInternal с общим сообщением, чтобы не раскрывать детали.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return Wrap(CodeRecordNotFound, "record not found", err)
+	case errors.Is(err, service.ErrReplayNotFound):
+		return Wrap(CodeReplayNotFound, "replay not found", err)
+	case errors.Is(err, service.ErrTooManyReplays):
+		return Wrap(CodeTooManyReplays, service.ErrTooManyReplays.Error(), err)
+	case errors.Is(err, service.ErrInvalidReplayRequest):
+		return Wrap(CodeInvalidArgument, service.ErrInvalidReplayRequest.Error(), err)
+	case errors.Is(err, service.ErrUnknownReplayTopic):
+		return Wrap(CodeInvalidArgument, service.ErrUnknownReplayTopic.Error(), err)
+	case errors.Is(err, service.ErrReplayTopicRequired):
+		return Wrap(CodeInvalidArgument, service.ErrReplayTopicRequired.Error(), err)
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return Wrap(CodeUnauthenticated, "unauthenticated", err)
+	case errors.Is(err, auth.ErrPermissionDenied):
+		return Wrap(CodePermissionDenied, "permission denied", err)
+	case errors.Is(err, context.Canceled):
+		return Wrap(CodeCanceled, "request canceled", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return Wrap(CodeDeadlineExceeded, "deadline exceeded", err)
+	default:
+		return Wrap(CodeInternal, "internal error", err)
+	}
+}
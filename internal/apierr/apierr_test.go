@@ -0,0 +1,62 @@
+package apierr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+)
+
+func TestFrom(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		code   Code
+		status int
+		grpc   codes.Code
+	}{
+		{"Record not found", fmt.Errorf("op: %w", repository.ErrNotFound), CodeRecordNotFound, http.StatusNotFound, codes.NotFound},
+		{"Replay not found", service.ErrReplayNotFound, CodeReplayNotFound, http.StatusNotFound, codes.NotFound},
+		{"Too many replays", service.ErrTooManyReplays, CodeTooManyReplays, http.StatusTooManyRequests, codes.ResourceExhausted},
+		{"Invalid replay", service.ErrInvalidReplayRequest, CodeInvalidArgument, http.StatusBadRequest, codes.InvalidArgument},
+		{"Unknown replay topic", fmt.Errorf("wrapped: %w", service.ErrUnknownReplayTopic), CodeInvalidArgument, http.StatusBadRequest, codes.InvalidArgument},
+		{"Replay topic required", service.ErrReplayTopicRequired, CodeInvalidArgument, http.StatusBadRequest, codes.InvalidArgument},
+		{"Unauthenticated", fmt.Errorf("%w: expired", auth.ErrUnauthenticated), CodeUnauthenticated, http.StatusUnauthorized, codes.Unauthenticated},
+		{"Permission denied", auth.ErrPermissionDenied, CodePermissionDenied, http.StatusForbidden, codes.PermissionDenied},
+		{"Deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), CodeDeadlineExceeded, http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		{"Canceled", fmt.Errorf("query: %w", context.Canceled), CodeCanceled, StatusClientClosedRequest, codes.Canceled},
+		{"Unknown", errors.New("connection refused"), CodeInternal, http.StatusInternalServerError, codes.Internal},
+		{"Already mapped", fmt.Errorf("op: %w", New(CodeRateLimited, "slow down")), CodeRateLimited, http.StatusTooManyRequests, codes.ResourceExhausted},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := From(tc.err)
+			assert.Equal(t, tc.code, e.Code)
+			assert.Equal(t, tc.status, e.Code.HTTPStatus())
+			assert.Equal(t, tc.grpc, e.Code.GRPCCode())
+		})
+	}
+
+	t.Run("Internal message hides cause", func(t *testing.T) {
+		e := From(errors.New("connection refused"))
+		assert.Equal(t, "internal error", e.Message)
+		assert.True(t, e.Internal())
+		assert.ErrorContains(t, e, "connection refused")
+	})
+
+	t.Run("Interrupted requests are not internal", func(t *testing.T) {
+		for _, err := range []error{context.Canceled, context.DeadlineExceeded} {
+			e := From(err)
+			assert.False(t, e.Internal())
+			assert.True(t, e.Interrupted())
+		}
+	})
+}
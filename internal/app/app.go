@@ -2,34 +2,55 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
+	"os"
 	"sync"
 
 	"github.com/Pavel26ru/aggregator-service/internal/app/grpc"
 	"github.com/Pavel26ru/aggregator-service/internal/app/http"
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
 	"github.com/Pavel26ru/aggregator-service/internal/config"
 	"github.com/Pavel26ru/aggregator-service/internal/ingestion"
 	"github.com/Pavel26ru/aggregator-service/internal/kafka"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/outbox"
+	"github.com/Pavel26ru/aggregator-service/internal/ratelimit"
 	"github.com/Pavel26ru/aggregator-service/internal/repository/postgres"
+	"github.com/Pavel26ru/aggregator-service/internal/schemaregistry"
+	"github.com/Pavel26ru/aggregator-service/internal/servertls"
 	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type App struct {
 	GRPCServer    *grpcapp.App
 	HTTPServer    *httpapp.App
 	KafkaProducer kafka.Producer
-	consumers     []kafka.Consumer
+	kafkaAdmin    *kafka.Admin
+	results       *kafka.ResultsPublisher
+	consumer      *consumerSupervisor
+	errCh         chan error
 	generator     *ingestion.Generator
 	db            *postgres.Database
 	logger        *slog.Logger
 }
 
-func New(ctx context.Context, logger *slog.Logger, cfg *config.Config) *App {
+func New(ctx context.Context, logger *slog.Logger, cfg *config.Config, tracerProvider trace.TracerProvider) *App {
 	log := logger.With(slog.String("component", "app"))
 
+	// === Metrics ===
+	// Всё, что отдаёт /metrics, регистрируется в одном реестре.
+	reg := metrics.NewRegistry()
+
 	// === DB ===
-	db, err := postgres.New(ctx, cfg.Postgres, log)
+	// В режиме exactly-once результаты публикует транзакционный консьюмер, а не outbox.
+	outboxTopic := cfg.Kafka.ResultsTopic
+	if cfg.Kafka.ExactlyOnce {
+		outboxTopic = ""
+	}
+	db, err := postgres.New(ctx, cfg.Postgres, outboxTopic, tracerProvider, log)
 	if err != nil {
 		panic(fmt.Errorf("failed to init db: %w", err))
 	}
@@ -37,14 +58,60 @@ func New(ctx context.Context, logger *slog.Logger, cfg *config.Config) *App {
 	// === Service ===
 	aggregatorService := service.New(log, db)
 
-	// === Kafka Topic ===
-	if err := kafka.EnsureTopic(ctx, cfg.Kafka.Brokers[0], cfg.Kafka.Topic, 10); err != nil {
-		panic(fmt.Errorf("failed to ensure kafka topic: %w", err))
+	// === Kafka Client Options ===
+	kafkaOpts, err := kafka.ClientOptions(cfg.Kafka, reg)
+	if err != nil {
+		panic(fmt.Errorf("failed to configure kafka client: %w", err))
+	}
+
+	// === Kafka Topics ===
+	bindingCfgs, err := cfg.Kafka.Bindings()
+	if err != nil {
+		panic(fmt.Errorf("failed to load kafka topic bindings: %w", err))
+	}
+	for topic, spec := range cfg.Kafka.TopicSpecs(bindingCfgs) {
+		if err := kafka.EnsureTopic(ctx, kafkaOpts, topic, spec, log); err != nil {
+			panic(fmt.Errorf("failed to ensure kafka topic %q: %w", topic, err))
+		}
+		log.Info("kafka topic ensured", slog.String("topic", topic))
+	}
+
+	// === Kafka Codecs ===
+	codecs := kafka.DefaultCodecs()
+	switch {
+	case cfg.SchemaRegistry.File != "":
+		resolver, err := schemaregistry.NewFileResolver(cfg.SchemaRegistry.File)
+		if err != nil {
+			panic(fmt.Errorf("failed to load local schema registry: %w", err))
+		}
+		codecs.SetSchemaRegistry(schemaregistry.NewDeserializer(resolver, log))
+	case cfg.SchemaRegistry.URL != "":
+		resolver := schemaregistry.NewHTTPResolver(
+			cfg.SchemaRegistry.URL,
+			cfg.SchemaRegistry.Username,
+			cfg.SchemaRegistry.Password,
+			cfg.SchemaRegistry.Timeout,
+		)
+		codecs.SetSchemaRegistry(schemaregistry.NewDeserializer(resolver, log))
+	}
+	producerCodec, err := codecs.ByName(cfg.Kafka.Codec)
+	if err != nil {
+		panic(fmt.Errorf("failed to select kafka codec: %w", err))
+	}
+
+	// === Topic Bindings ===
+	bindings, err := kafka.NewBindings(bindingCfgs, cfg.Validation, codecs)
+	if err != nil {
+		panic(fmt.Errorf("failed to init kafka topic bindings: %w", err))
 	}
-	log.Info("kafka topic ensured", slog.String("topic", cfg.Kafka.Topic))
 
 	// === Kafka Producer ===
-	producer, err := kafka.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.Topic, log)
+	host, _ := os.Hostname()
+	producerID := cfg.Kafka.ProducerID
+	if producerID == "" {
+		producerID = host
+	}
+	producer, err := kafka.NewProducer(kafkaOpts, cfg.Kafka.Topic, producerID, producerCodec, tracerProvider, log)
 	if err != nil {
 		panic(fmt.Errorf("failed to create kafka producer: %w", err))
 	}
@@ -56,39 +123,120 @@ func New(ctx context.Context, logger *slog.Logger, cfg *config.Config) *App {
 		generator.Start(ctx)
 	}()
 
-	// === Kafka Consumers ===
-	var consumers []kafka.Consumer
-	for i := 0; i < cfg.Workers; i++ {
-		consumerLog := log.With(slog.Int("worker_id", i+1))
-		consumer, err := kafka.NewConsumer(
-			cfg.Kafka.Brokers,
-			cfg.Kafka.Group,
-			cfg.Kafka.Topic,
-			aggregatorService,
-			consumerLog,
-		)
+	// === Outbox Relay ===
+	var results *kafka.ResultsPublisher
+	if outboxTopic != "" {
+		results, err = kafka.NewResultsPublisher(kafkaOpts, log)
 		if err != nil {
-			panic(fmt.Errorf("failed to create kafka consumer worker %d: %w", i+1, err))
+			panic(fmt.Errorf("failed to create kafka results publisher: %w", err))
 		}
-		consumers = append(consumers, consumer)
 
+		relay := outbox.NewRelay(db, results, cfg.Outbox, log)
 		go func() {
-			consumerLog.Info("starting consumer worker")
-			if err := consumer.Run(ctx); err != nil {
-				consumerLog.Error("consumer worker failed", slog.Any("error", err))
-			}
+			log.Info("starting outbox relay", slog.String("topic", cfg.Kafka.ResultsTopic))
+			relay.Start(ctx)
 		}()
 	}
 
+	// === Kafka Consumer ===
+	transactionalID := cfg.Kafka.TransactionalID
+	if transactionalID == "" {
+		transactionalID = cfg.Kafka.Group + "-" + host
+	}
+
+	consumer, err := newConsumerSupervisor(
+		func() (kafka.Consumer, error) {
+			if cfg.Kafka.ExactlyOnce {
+				return kafka.NewTransactConsumer(
+					kafkaOpts,
+					cfg.Kafka,
+					transactionalID,
+					cfg.Workers,
+					aggregatorService,
+					bindings,
+					tracerProvider,
+					log,
+				)
+			}
+			return kafka.NewConsumer(
+				kafkaOpts,
+				cfg.Kafka,
+				cfg.Workers,
+				aggregatorService,
+				bindings,
+				tracerProvider,
+				log,
+			)
+		},
+		cfg.Kafka.ConsumerMaxRestarts,
+		cfg.Kafka.ConsumerRestartBackoff,
+		log,
+	)
+	if err != nil {
+		panic(fmt.Errorf("failed to create kafka consumer: %w", err))
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("starting kafka consumer",
+			slog.Int("workers", cfg.Workers),
+			slog.Bool("exactly_once", cfg.Kafka.ExactlyOnce),
+		)
+		if err := consumer.run(ctx); err != nil {
+			log.Error("kafka consumer failed", slog.Any("error", err))
+			errCh <- err
+		}
+	}()
+
+	// === Admin ===
+	kafkaAdmin, err := kafka.NewAdmin(kafkaOpts, cfg.Kafka.Group)
+	if err != nil {
+		panic(fmt.Errorf("failed to create kafka admin client: %w", err))
+	}
+	replayer := kafka.NewReplayer(kafkaOpts, aggregatorService, bindings, tracerProvider, log)
+	adminService := service.NewAdmin(ctx, log, kafkaAdmin, replayer,
+		cfg.Replay.MaxConcurrent,
+		cfg.Replay.Retention,
+	)
+
+	// === Auth ===
+	authn, err := auth.New(ctx, cfg.Auth, log)
+	if err != nil {
+		panic(fmt.Errorf("failed to init auth: %w", err))
+	}
+	if authn == nil {
+		log.Warn("authentication is disabled, REST and gRPC APIs are open")
+	}
+
+	// === TLS ===
+	var grpcTLS, httpTLS *tls.Config
+	if cfg.GRPC.TLS.Enabled {
+		reloader, err := servertls.New(cfg.GRPC.TLS, log)
+		if err != nil {
+			panic(fmt.Errorf("failed to init grpc tls: %w", err))
+		}
+		grpcTLS = reloader.ServerConfig("h2")
+	}
+	if cfg.HTTP.TLS.Enabled {
+		reloader, err := servertls.New(cfg.HTTP.TLS, log)
+		if err != nil {
+			panic(fmt.Errorf("failed to init http tls: %w", err))
+		}
+		httpTLS = reloader.ServerConfig("h2", "http/1.1")
+	}
+
+	// === Rate Limit ===
+	limiter := ratelimit.New(cfg.RateLimit)
+
 	// === Servers ===
-	grpcApp := grpcapp.New(ctx, log, aggregatorService, cfg.GRPC.Addr())
+	grpcApp := grpcapp.New(ctx, log, aggregatorService, adminService, cfg.GRPC, authn, grpcTLS, reg, tracerProvider, limiter)
 	go func() {
 		if err := grpcApp.Run(); err != nil {
 			log.Error("gRPC server failed", slog.Any("error", err))
 		}
 	}()
 
-	httpApp := httpapp.New(ctx, log, aggregatorService, cfg.HTTP.Addr())
+	httpApp := httpapp.New(ctx, log, aggregatorService, adminService, cfg.HTTP.Addr(), httpTLS, reg, tracerProvider, authn, cfg.Auth.MetricsPublic, limiter, cfg.HTTP.TrustedProxies)
 	go func() {
 		if err := httpApp.Run(); err != nil {
 			log.Error("http server failed", slog.Any("error", err))
@@ -99,13 +247,22 @@ func New(ctx context.Context, logger *slog.Logger, cfg *config.Config) *App {
 		GRPCServer:    grpcApp,
 		HTTPServer:    httpApp,
 		KafkaProducer: producer,
-		consumers:     consumers,
+		kafkaAdmin:    kafkaAdmin,
+		results:       results,
+		consumer:      consumer,
+		errCh:         errCh,
 		generator:     generator,
 		db:            db,
 		logger:        log,
 	}
 }
 
+// Err возвращает канал, в который приходит ошибка компонента,
+// после которой приложение не может продолжать работу.
+func (a *App) Err() <-chan error {
+	return a.errCh
+}
+
 func (a *App) Stop(ctx context.Context) error {
 	a.logger.Info("stopping application components")
 
@@ -129,9 +286,11 @@ func (a *App) Stop(ctx context.Context) error {
 	// Stop Kafka producer
 	a.KafkaProducer.Close()
 
-	// Stop Kafka consumers
-	for _, c := range a.consumers {
-		c.Close()
+	// Stop Kafka consumer
+	a.consumer.close()
+	a.kafkaAdmin.Close()
+	if a.results != nil {
+		a.results.Close()
 	}
 
 	// Stop DB
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/kafka"
+)
+
+// consumerSupervisor запускает консьюмер и пересоздаёт его после фатальной
+// ошибки Run не более maxRestarts раз; дальше ошибка возвращается приложению.
+type consumerSupervisor struct {
+	newConsumer func() (kafka.Consumer, error)
+	maxRestarts int
+	backoff     time.Duration
+	log         *slog.Logger
+
+	mu      sync.Mutex
+	current kafka.Consumer
+	closed  bool
+}
+
+func newConsumerSupervisor(
+	newConsumer func() (kafka.Consumer, error),
+	maxRestarts int,
+	backoff time.Duration,
+	log *slog.Logger,
+) (*consumerSupervisor, error) {
+	c, err := newConsumer()
+	if err != nil {
+		return nil, err
+	}
+
+	return &consumerSupervisor{
+		newConsumer: newConsumer,
+		maxRestarts: maxRestarts,
+		backoff:     backoff,
+		log:         log,
+		current:     c,
+	}, nil
+}
+
+// run возвращает nil после отмены ctx или close и ошибку, если перезапуски исчерпаны.
+func (s *consumerSupervisor) run(ctx context.Context) error {
+	for restarts := 0; ; restarts++ {
+		s.mu.Lock()
+		c := s.current
+		s.mu.Unlock()
+		if c == nil {
+			return nil
+		}
+
+		err := c.Run(ctx)
+		if err == nil {
+			return nil
+		}
+		c.Close()
+
+		if restarts >= s.maxRestarts {
+			return fmt.Errorf("kafka consumer failed after %d restarts: %w", restarts, err)
+		}
+		s.log.Error("kafka consumer failed, restarting",
+			slog.Any("error", err),
+			slog.Int("restart", restarts+1),
+			slog.Duration("backoff", s.backoff),
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.backoff):
+		}
+
+		next, err := s.newConsumer()
+		if err != nil {
+			return fmt.Errorf("failed to recreate kafka consumer: %w", err)
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			next.Close()
+			return nil
+		}
+		s.current = next
+		s.mu.Unlock()
+	}
+}
+
+// close останавливает текущий консьюмер и запрещает перезапуски.
+func (s *consumerSupervisor) close() {
+	s.mu.Lock()
+	c := s.current
+	s.current = nil
+	s.closed = true
+	s.mu.Unlock()
+
+	if c != nil {
+		c.Close()
+	}
+}
@@ -2,14 +2,24 @@ package grpcapp
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"net"
+	"strings"
 
 	grpcMaxValue "github.com/Pavel26ru/aggregator-service/gen"
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/ratelimit"
 	"github.com/Pavel26ru/aggregator-service/internal/service"
 	grpchandler "github.com/Pavel26ru/aggregator-service/internal/transport/grpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -19,12 +29,85 @@ type App struct {
 	address    string
 }
 
-func New(ctx context.Context, logger *slog.Logger, s *service.Service, address string) *App {
-	gRPCServer := grpc.NewServer()
+// methodScopes — области доступа методов. Остальные методы, включая reflection,
+// требуют ScopeRead, неизвестные сервисы — ScopeAdmin.
+var methodScopes = map[string]auth.Scope{
+	grpcMaxValue.AggregatorService_GetMax_FullMethodName:        auth.ScopeRead,
+	grpcMaxValue.AggregatorService_ListRevisions_FullMethodName: auth.ScopeRead,
+
+	grpcMaxValue.AdminService_GetConsumerGroupStatus_FullMethodName: auth.ScopeAdmin,
+	grpcMaxValue.AdminService_GetReplay_FullMethodName:              auth.ScopeAdmin,
+	grpcMaxValue.AdminService_StartReplay_FullMethodName:            auth.ScopeAdmin,
+	grpcMaxValue.AdminService_CancelReplay_FullMethodName:           auth.ScopeAdmin,
+}
+
+func methodScope(fullMethod string) auth.Scope {
+	if scope, ok := methodScopes[fullMethod]; ok {
+		return scope
+	}
+	if strings.HasPrefix(fullMethod, "/grpc.reflection.") {
+		return auth.ScopeRead
+	}
+	return auth.ScopeAdmin
+}
+
+// requestClass относит GetMax без uuid (запрос за период) к дорогим вызовам.
+func requestClass(fullMethod string, req any) ratelimit.Class {
+	if r, ok := req.(*grpcMaxValue.GetMaxRequest); ok && fullMethod == grpcMaxValue.AggregatorService_GetMax_FullMethodName && r.Uuid == "" {
+		return ratelimit.ClassPeriod
+	}
+	return ratelimit.ClassDefault
+}
+
+// New создаёт сервер и регистрирует его метрики в reg. authn == nil выключает
+// аутентификацию, tlsCfg == nil — TLS,
+// limiter == nil — ограничение частоты вызовов.
+func New(
+	ctx context.Context,
+	logger *slog.Logger,
+	s *service.Service,
+	a *service.Admin,
+	cfg config.GRPCConfig,
+	authn *auth.Authenticator,
+	tlsCfg *tls.Config,
+	reg prometheus.Registerer,
+	tp trace.TracerProvider,
+	limiter *ratelimit.Limiter,
+) *App {
+	m := metrics.NewGRPC(reg)
+
+	// Перехватчики выполняются по порядку: идентификатор запроса нужен всем
+	// остальным, а журнал и метрики видят код ответа после восстановления от паники.
+	opts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(tp))),
+		grpc.ChainUnaryInterceptor(
+			grpchandler.RequestIDUnaryInterceptor,
+			grpchandler.AccessLogUnaryInterceptor(logger),
+			m.UnaryServerInterceptor,
+			grpchandler.RecoveryUnaryInterceptor(logger),
+			grpchandler.AuthUnaryInterceptor(authn, methodScope, logger),
+			grpchandler.RateLimitUnaryInterceptor(limiter, requestClass, logger),
+			grpchandler.DeadlineUnaryInterceptor(cfg),
+		),
+		grpc.ChainStreamInterceptor(
+			grpchandler.RequestIDStreamInterceptor,
+			grpchandler.AccessLogStreamInterceptor(logger),
+			m.StreamServerInterceptor,
+			grpchandler.RecoveryStreamInterceptor(logger),
+			grpchandler.AuthStreamInterceptor(authn, methodScope, logger),
+			grpchandler.RateLimitStreamInterceptor(limiter, logger),
+			grpchandler.DeadlineStreamInterceptor(cfg),
+		),
+	}
+	if tlsCfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
+	gRPCServer := grpc.NewServer(opts...)
 	handler := grpchandler.NewHandler(s, logger)
 	grpcMaxValue.RegisterAggregatorServiceServer(gRPCServer, handler)
+	grpcMaxValue.RegisterAdminServiceServer(gRPCServer, grpchandler.NewAdminHandler(a, logger))
 	reflection.Register(gRPCServer) // Register reflection service
-	return &App{gRPCServer: gRPCServer, logger: logger, address: address}
+	return &App{gRPCServer: gRPCServer, logger: logger, address: cfg.Addr()}
 }
 
 func (a *App) Run() error {
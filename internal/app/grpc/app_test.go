@@ -0,0 +1,54 @@
+package grpcapp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	grpcMaxValue "github.com/Pavel26ru/aggregator-service/gen"
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	grpchandler "github.com/Pavel26ru/aggregator-service/internal/transport/grpc"
+)
+
+func TestMethodScope_Replay(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name":"loader","key":"i-key","scopes":["ingest"]},
+		{"name":"ops","key":"a-key","scopes":["admin"]}
+	]`), 0o600))
+	authn, err := auth.New(context.Background(), config.AuthConfig{Enabled: true, APIKeysFile: path}, logger)
+	require.NoError(t, err)
+
+	interceptor := grpchandler.AuthUnaryInterceptor(authn, methodScope, logger)
+	call := func(method, key string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpchandler.APIKeyHeader, key))
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, any) (any, error) {
+			return nil, nil
+		})
+		return err
+	}
+
+	methods := []string{
+		grpcMaxValue.AdminService_StartReplay_FullMethodName,
+		grpcMaxValue.AdminService_GetReplay_FullMethodName,
+		grpcMaxValue.AdminService_CancelReplay_FullMethodName,
+	}
+	for _, method := range methods {
+		t.Run(method, func(t *testing.T) {
+			assert.Equal(t, codes.PermissionDenied, status.Code(call(method, "i-key")))
+			assert.NoError(t, call(method, "a-key"))
+		})
+	}
+}
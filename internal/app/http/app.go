@@ -2,13 +2,20 @@ package httpapp
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"time"
 
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/ratelimit"
 	"github.com/Pavel26ru/aggregator-service/internal/service"
 	"github.com/Pavel26ru/aggregator-service/internal/transport/rest"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type App struct {
@@ -17,14 +24,32 @@ type App struct {
 	address    string
 }
 
-func New(ctx context.Context, logger *slog.Logger, s *service.Service, address string) *App {
+// New регистрирует метрики HTTP в reg и отдаёт его содержимое на /metrics.
+// authn == nil выключает аутентификацию, tlsCfg == nil — TLS, limiter == nil —
+// ограничение частоты запросов. Заголовкам X-Forwarded-For и X-Real-IP верят
+// только от trustedProxies.
+func New(
+	ctx context.Context,
+	logger *slog.Logger,
+	s *service.Service,
+	a *service.Admin,
+	address string,
+	tlsCfg *tls.Config,
+	reg *prometheus.Registry,
+	tp trace.TracerProvider,
+	authn *auth.Authenticator,
+	publicMetrics bool,
+	limiter *ratelimit.Limiter,
+	trustedProxies []netip.Prefix,
+) *App {
 	log := logger.With(slog.String("component", "httpapp"))
 
-	router := rest.New(s, log)
+	router := rest.New(s, a, log, metrics.NewHTTP(reg), reg, tp, authn, publicMetrics, limiter, trustedProxies)
 
 	httpServer := &http.Server{
-		Addr:    address,
-		Handler: router,
+		Addr:      address,
+		Handler:   router,
+		TLSConfig: tlsCfg,
 	}
 
 	return &App{
@@ -38,9 +63,17 @@ func (a *App) Run() error {
 	const op = "httpapp.Run"
 	log := a.logger.With(slog.String("op", op))
 
-	log.Info("http server is running", slog.String("address", a.address))
+	tlsEnabled := a.httpServer.TLSConfig != nil
+	log.Info("http server is running", slog.String("address", a.address), slog.Bool("tls", tlsEnabled))
 
-	if err := a.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	var err error
+	if tlsEnabled {
+		// Сертификат отдаёт TLSConfig, поэтому пути к файлам не нужны.
+		err = a.httpServer.ListenAndServeTLS("", "")
+	} else {
+		err = a.httpServer.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
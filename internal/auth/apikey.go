@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// APIKey — запись файла ключей. Вместо самого ключа можно хранить
+// его SHA-256 в hex, чтобы файл не содержал секретов в открытом виде.
+type APIKey struct {
+	Name      string  `json:"name"`
+	Key       string  `json:"key"`
+	KeySHA256 string  `json:"key_sha256"`
+	Scopes    []Scope `json:"scopes"`
+}
+
+// APIKeys — статические ключи. Ключи сравниваются по хешу, поэтому время
+// поиска не зависит от совпадающего префикса.
+type APIKeys struct {
+	byHash map[[sha256.Size]byte]*Principal
+}
+
+func NewAPIKeys(keys []APIKey) (*APIKeys, error) {
+	const op = "auth.NewAPIKeys"
+
+	byHash := make(map[[sha256.Size]byte]*Principal, len(keys))
+	for i, k := range keys {
+		if k.Name == "" {
+			return nil, fmt.Errorf("%s: key #%d has no name", op, i)
+		}
+
+		var hash [sha256.Size]byte
+		switch {
+		case k.Key != "" && k.KeySHA256 != "":
+			return nil, fmt.Errorf("%s: key '%s' sets both key and key_sha256", op, k.Name)
+		case k.Key != "":
+			hash = sha256.Sum256([]byte(k.Key))
+		case k.KeySHA256 != "":
+			decoded, err := hex.DecodeString(k.KeySHA256)
+			if err != nil || len(decoded) != sha256.Size {
+				return nil, fmt.Errorf("%s: key '%s' has invalid key_sha256", op, k.Name)
+			}
+			copy(hash[:], decoded)
+		default:
+			return nil, fmt.Errorf("%s: key '%s' has no key or key_sha256", op, k.Name)
+		}
+
+		if _, ok := byHash[hash]; ok {
+			return nil, fmt.Errorf("%s: key '%s' duplicates another key", op, k.Name)
+		}
+		byHash[hash] = &Principal{Subject: k.Name, Method: MethodAPIKey, Scopes: k.Scopes}
+	}
+
+	return &APIKeys{byHash: byHash}, nil
+}
+
+// LoadAPIKeys читает JSON-массив APIKey из файла.
+func LoadAPIKeys(path string) (*APIKeys, error) {
+	const op = "auth.LoadAPIKeys"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return NewAPIKeys(keys)
+}
+
+func (k *APIKeys) Authenticate(key string) (*Principal, error) {
+	p, ok := k.byHash[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown api key", ErrUnauthenticated)
+	}
+	return p, nil
+}
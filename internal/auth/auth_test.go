@@ -0,0 +1,333 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+)
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	hash := sha256.Sum256([]byte("hashed-secret"))
+	keys, err := NewAPIKeys([]APIKey{
+		{Name: "grafana", Key: "plain-secret", Scopes: []Scope{ScopeRead}},
+		{Name: "ops", KeySHA256: hex.EncodeToString(hash[:]), Scopes: []Scope{ScopeAdmin}},
+	})
+	require.NoError(t, err)
+
+	t.Run("Plain key", func(t *testing.T) {
+		p, err := keys.Authenticate("plain-secret")
+		require.NoError(t, err)
+		assert.Equal(t, "grafana", p.Subject)
+		assert.True(t, p.HasScope(ScopeRead))
+		assert.False(t, p.HasScope(ScopeIngest))
+	})
+
+	t.Run("Hashed key", func(t *testing.T) {
+		p, err := keys.Authenticate("hashed-secret")
+		require.NoError(t, err)
+		assert.Equal(t, "ops", p.Subject)
+		assert.True(t, p.HasScope(ScopeIngest), "admin implies other scopes")
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		_, err := keys.Authenticate("nope")
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("Invalid file entries", func(t *testing.T) {
+		_, err := NewAPIKeys([]APIKey{{Name: "a", Key: "x"}, {Name: "b", Key: "x"}})
+		assert.Error(t, err)
+		_, err = NewAPIKeys([]APIKey{{Name: "a"}})
+		assert.Error(t, err)
+		_, err = NewAPIKeys([]APIKey{{Key: "x"}})
+		assert.Error(t, err)
+	})
+}
+
+func TestJWTValidator_Authenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := writeJWKS(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey))
+	keys, err := NewFileKeySet(path)
+	require.NoError(t, err)
+	v := NewJWTValidator(keys, "https://issuer.example", "aggregator")
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "svc-reporting",
+			"iss":   "https://issuer.example",
+			"aud":   "aggregator",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": "read ingest",
+		}
+	}
+
+	t.Run("RSA token", func(t *testing.T) {
+		p, err := v.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, valid()))
+		require.NoError(t, err)
+		assert.Equal(t, "svc-reporting", p.Subject)
+		assert.Equal(t, MethodJWT, p.Method)
+		assert.ElementsMatch(t, []Scope{ScopeRead, ScopeIngest}, p.Scopes)
+	})
+
+	t.Run("EC token with scp claim", func(t *testing.T) {
+		c := valid()
+		delete(c, "scope")
+		c["scp"] = []string{"admin"}
+		p, err := v.Authenticate(context.Background(), signToken(t, jwt.SigningMethodES256, "ec-1", ecKey, c))
+		require.NoError(t, err)
+		assert.True(t, p.HasScope(ScopeAdmin))
+	})
+
+	rejected := map[string]func() string{
+		"Expired": func() string {
+			c := valid()
+			c["exp"] = time.Now().Add(-time.Hour).Unix()
+			return signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, c)
+		},
+		"Missing exp": func() string {
+			c := valid()
+			delete(c, "exp")
+			return signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, c)
+		},
+		"Wrong issuer": func() string {
+			c := valid()
+			c["iss"] = "https://evil.example"
+			return signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, c)
+		},
+		"Wrong audience": func() string {
+			c := valid()
+			c["aud"] = "other"
+			return signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, c)
+		},
+		"Unknown kid": func() string {
+			return signToken(t, jwt.SigningMethodRS256, "rsa-2", rsaKey, valid())
+		},
+		"Key of another kid": func() string {
+			return signToken(t, jwt.SigningMethodES256, "rsa-1", ecKey, valid())
+		},
+		"HMAC": func() string {
+			return signToken(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), valid())
+		},
+	}
+	for name, token := range rejected {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Authenticate(context.Background(), token())
+			assert.ErrorIs(t, err, ErrUnauthenticated)
+		})
+	}
+}
+
+func TestHTTPKeySet_Rotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var (
+		doc      atomic.Value
+		requests atomic.Int64
+	)
+	doc.Store(jwksDocument(rsaJWK("old", &oldKey.PublicKey)))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(doc.Load().([]byte))
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	keys, err := NewHTTPKeySet(context.Background(), srv.URL, time.Hour, time.Second, logger)
+	require.NoError(t, err)
+	v := NewJWTValidator(keys, "", "")
+
+	claims := jwt.MapClaims{"sub": "svc", "exp": time.Now().Add(time.Hour).Unix()}
+	_, err = v.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, "old", oldKey, claims))
+	require.NoError(t, err)
+
+	// Провайдер выпустил новый ключ: неизвестный kid вызывает обновление набора.
+	doc.Store(jwksDocument(rsaJWK("old", &oldKey.PublicKey), rsaJWK("new", &newKey.PublicKey)))
+	keys.attemptedAt = time.Time{}
+
+	_, err = v.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, "new", newKey, claims))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), requests.Load())
+
+	// Повторные неизвестные kid не приводят к запросам чаще minRefetchInterval.
+	for range 5 {
+		_, err = v.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, "forged", newKey, claims))
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	}
+	assert.Equal(t, int64(2), requests.Load())
+}
+
+func TestHTTPKeySet_ConcurrentRefresh(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var (
+		doc      atomic.Value
+		requests atomic.Int64
+	)
+	release := make(chan struct{})
+	doc.Store(jwksDocument(rsaJWK("old", &oldKey.PublicKey)))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) > 1 {
+			<-release
+		}
+		_, _ = w.Write(doc.Load().([]byte))
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	keys, err := NewHTTPKeySet(context.Background(), srv.URL, time.Hour, 5*time.Second, logger)
+	require.NoError(t, err)
+	doc.Store(jwksDocument(rsaJWK("old", &oldKey.PublicKey), rsaJWK("new", &newKey.PublicKey)))
+	keys.attemptedAt = time.Time{}
+
+	// Запрос, начавший обновление, отменён: остальные всё равно получают новый ключ.
+	canceled, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := keys.Key(canceled, "new")
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return requests.Load() == 2 }, time.Second, time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	case <-time.After(time.Second):
+		t.Fatal("canceled request waited for the refresh")
+	}
+
+	t.Run("Known keys do not wait for refresh", func(t *testing.T) {
+		key, err := keys.Key(context.Background(), "old")
+		require.NoError(t, err)
+		assert.NotNil(t, key)
+	})
+
+	results := make(chan error, 5)
+	for range 5 {
+		go func() {
+			_, err := keys.Key(context.Background(), "new")
+			results <- err
+		}()
+	}
+	close(release)
+	for range 5 {
+		assert.NoError(t, <-results)
+	}
+	assert.Equal(t, int64(2), requests.Load())
+}
+
+func TestNew(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("Disabled", func(t *testing.T) {
+		a, err := New(context.Background(), config.AuthConfig{}, logger)
+		require.NoError(t, err)
+		assert.Nil(t, a)
+	})
+
+	t.Run("Enabled without sources", func(t *testing.T) {
+		_, err := New(context.Background(), config.AuthConfig{Enabled: true}, logger)
+		assert.Error(t, err)
+	})
+
+	t.Run("API keys only", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.json")
+		require.NoError(t, os.WriteFile(path, []byte(`[{"name":"ci","key":"k1","scopes":["read"]}]`), 0o600))
+
+		a, err := New(context.Background(), config.AuthConfig{Enabled: true, APIKeysFile: path}, logger)
+		require.NoError(t, err)
+
+		p, err := a.Authorize(context.Background(), "k1", ScopeRead)
+		require.NoError(t, err)
+		assert.Equal(t, "ci", p.Subject)
+
+		_, err = a.Authorize(context.Background(), "k1", ScopeAdmin)
+		assert.ErrorIs(t, err, ErrPermissionDenied)
+
+		_, err = a.Authorize(context.Background(), "", ScopeRead)
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+
+		_, err = a.Authorize(context.Background(), "a.b.c", ScopeRead)
+		assert.ErrorIs(t, err, ErrUnauthenticated, "jwt is not configured")
+	})
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func rsaJWK(kid string, pub *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, pub *ecdsa.PublicKey) map[string]string {
+	raw, err := pub.Bytes()
+	if err != nil {
+		panic(err)
+	}
+	size := (len(raw) - 1) / 2
+	return map[string]string{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(raw[1 : 1+size]),
+		"y":   base64.RawURLEncoding.EncodeToString(raw[1+size:]),
+	}
+}
+
+func jwksDocument(keys ...map[string]string) []byte {
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func writeJWKS(t *testing.T, keys ...map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksDocument(keys...), 0o600))
+	return path
+}
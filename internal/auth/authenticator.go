@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+)
+
+// Authenticator принимает API-ключи и JWT. Токен из трёх частей через точку
+// проверяется как JWT, остальное — как API-ключ.
+type Authenticator struct {
+	apiKeys *APIKeys
+	jwt     *JWTValidator
+}
+
+// New возвращает nil, если аутентификация выключена.
+func New(ctx context.Context, cfg config.AuthConfig, log *slog.Logger) (*Authenticator, error) {
+	const op = "auth.New"
+
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	a := &Authenticator{}
+	if cfg.APIKeysFile != "" {
+		keys, err := LoadAPIKeys(cfg.APIKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		a.apiKeys = keys
+	}
+
+	var keySet KeySet
+	switch {
+	case cfg.JWKSFile != "" && cfg.JWKSURL != "":
+		return nil, fmt.Errorf("%s: jwks file and url are mutually exclusive", op)
+	case cfg.JWKSFile != "":
+		fileKeys, err := NewFileKeySet(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		keySet = fileKeys
+	case cfg.JWKSURL != "":
+		httpKeys, err := NewHTTPKeySet(ctx, cfg.JWKSURL, cfg.JWKSRefresh, cfg.JWKSTimeout, log)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		keySet = httpKeys
+	}
+	if keySet != nil {
+		a.jwt = NewJWTValidator(keySet, cfg.JWTIssuer, cfg.JWTAudience)
+	}
+
+	if a.apiKeys == nil && a.jwt == nil {
+		return nil, fmt.Errorf("%s: auth is enabled but neither api keys nor jwks are configured", op)
+	}
+
+	return a, nil
+}
+
+// Authenticate проверяет токен и возвращает клиента или ошибку, обёрнутую
+// в ErrUnauthenticated.
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	if token == "" {
+		return nil, fmt.Errorf("%w: missing credentials", ErrUnauthenticated)
+	}
+
+	if strings.Count(token, ".") == 2 {
+		if a.jwt == nil {
+			return nil, fmt.Errorf("%w: jwt authentication is not configured", ErrUnauthenticated)
+		}
+		return a.jwt.Authenticate(ctx, token)
+	}
+
+	if a.apiKeys == nil {
+		return nil, fmt.Errorf("%w: api key authentication is not configured", ErrUnauthenticated)
+	}
+	return a.apiKeys.Authenticate(token)
+}
+
+// Authorize аутентифицирует токен и проверяет область доступа.
+func (a *Authenticator) Authorize(ctx context.Context, token string, scope Scope) (*Principal, error) {
+	p, err := a.Authenticate(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !p.HasScope(scope) {
+		return p, fmt.Errorf("%w: scope '%s' required", ErrPermissionDenied, scope)
+	}
+	return p, nil
+}
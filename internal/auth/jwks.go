@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var ErrKeyNotFound = errors.New("signing key not found")
+
+// KeySet находит открытый ключ для проверки подписи JWT по kid из заголовка.
+type KeySet interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS разбирает документ JWKS (RFC 7517). Поддерживаются ключи RSA,
+// EC (P-256, P-384, P-521) и OKP Ed25519; ключи шифрования пропускаются.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key '%s': %w", k.Kid, err)
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeB64(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeB64(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("e: %w", err)
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() < 2 || exp.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := decodeB64(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeB64(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid coordinate length")
+		}
+		return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := decodeB64(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid key length")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", k.Kty)
+	}
+}
+
+func decodeB64(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("missing")
+	}
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// lookupKey ищет ключ по kid. Токен без kid принимается, только если ключ один.
+func lookupKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, bool) {
+	if key, ok := keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// FileKeySet — ключи из локального файла JWKS, читается один раз при старте.
+type FileKeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+func NewFileKeySet(path string) (*FileKeySet, error) {
+	const op = "auth.NewFileKeySet"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &FileKeySet{keys: keys}, nil
+}
+
+func (s *FileKeySet) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok := lookupKey(s.keys, kid)
+	if !ok {
+		return nil, fmt.Errorf("kid '%s': %w", kid, ErrKeyNotFound)
+	}
+	return key, nil
+}
+
+// minRefetchInterval ограничивает внеочередные обновления из-за неизвестного kid,
+// чтобы токены с произвольным kid не превращались в запросы к провайдеру.
+const minRefetchInterval = 10 * time.Second
+
+// HTTPKeySet — ключи, загружаемые по URL провайдера (jwks_uri). Набор
+// обновляется раз в refresh и при появлении неизвестного kid, например после
+// ротации ключей. Если обновление не удалось, используются прежние ключи.
+// Обновление идёт без блокировки: проверка токенов с известными ключами его не ждёт,
+// а одновременные запросы с неизвестным kid ждут один общий запрос к провайдеру.
+type HTTPKeySet struct {
+	url     string
+	refresh time.Duration
+	client  *http.Client
+	log     *slog.Logger
+	group   singleflight.Group
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// NewHTTPKeySet загружает ключи сразу, чтобы ошибка адреса проявилась при старте.
+func NewHTTPKeySet(ctx context.Context, url string, refresh, timeout time.Duration, log *slog.Logger) (*HTTPKeySet, error) {
+	const op = "auth.NewHTTPKeySet"
+
+	s := &HTTPKeySet{
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: timeout},
+		log:     log.With(slog.String("component", "jwks")),
+	}
+	keys, err := s.fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	s.attemptedAt = s.fetchedAt
+
+	return s, nil
+}
+
+func (s *HTTPKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	key, ok := lookupKey(s.keys, kid)
+	stale := s.refresh > 0 && time.Since(s.fetchedAt) > s.refresh
+	s.mu.Unlock()
+
+	if stale || !ok {
+		// Отмена запроса, начавшего обновление, не должна обрывать его для остальных,
+		// поэтому ждём результат, пока жив ctx, а само обновление идёт без него.
+		select {
+		case res := <-s.group.DoChan("refresh", func() (any, error) { return nil, s.update() }):
+			if res.Err != nil {
+				s.log.WarnContext(ctx, "failed to refresh jwks, keeping previous keys", slog.Any("error", res.Err))
+			}
+		case <-ctx.Done():
+		}
+
+		s.mu.Lock()
+		key, ok = lookupKey(s.keys, kid)
+		s.mu.Unlock()
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("kid '%s': %w", kid, ErrKeyNotFound)
+	}
+	return key, nil
+}
+
+// update загружает набор, если с прошлой попытки прошло больше minRefetchInterval.
+// Длительность запроса ограничена таймаутом клиента.
+func (s *HTTPKeySet) update() error {
+	s.mu.Lock()
+	if time.Since(s.attemptedAt) <= minRefetchInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.attemptedAt = time.Now()
+	s.mu.Unlock()
+
+	keys, err := s.fetch(context.Background())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *HTTPKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return parseJWKS(data)
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// clockSkew — допустимое расхождение часов при проверке exp и nbf.
+const clockSkew = 30 * time.Second
+
+// signingMethods — только асимметричные алгоритмы: HMAC с ключами из JWKS
+// позволил бы подделать токен открытым ключом.
+var signingMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// claims — области доступа читаются из scope (строка через пробел, RFC 8693)
+// или scp (строка или массив).
+type claims struct {
+	jwt.RegisteredClaims
+	Scope string           `json:"scope"`
+	Scp   jwt.ClaimStrings `json:"scp"`
+}
+
+func (c *claims) scopes() []Scope {
+	var out []Scope
+	for _, s := range strings.Fields(c.Scope) {
+		out = append(out, Scope(s))
+	}
+	for _, s := range c.Scp {
+		out = append(out, Scope(s))
+	}
+	return out
+}
+
+// JWTValidator проверяет подпись, срок действия и, если заданы, iss и aud токена.
+type JWTValidator struct {
+	keys   KeySet
+	parser *jwt.Parser
+}
+
+func NewJWTValidator(keys KeySet, issuer, audience string) *JWTValidator {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+
+	return &JWTValidator{keys: keys, parser: jwt.NewParser(opts...)}
+}
+
+func (v *JWTValidator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	var c claims
+	_, err := v.parser.ParseWithClaims(token, &c, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnauthenticated, err)
+	}
+
+	return &Principal{Subject: c.Subject, Method: MethodJWT, Scopes: c.scopes()}, nil
+}
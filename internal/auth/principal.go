@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// Scope — область доступа клиента.
+type Scope string
+
+const (
+	// ScopeRead — чтение агрегатов.
+	ScopeRead Scope = "read"
+	// ScopeIngest — операции, которые записывают данные.
+	ScopeIngest Scope = "ingest"
+	// ScopeAdmin — состояние конвейера, метрики и управление; включает остальные области.
+	ScopeAdmin Scope = "admin"
+)
+
+var (
+	// ErrUnauthenticated — учётные данные не переданы или недействительны.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrPermissionDenied — у клиента нет нужной области доступа.
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// Principal — аутентифицированный клиент.
+type Principal struct {
+	// Subject — имя API-ключа или claim sub токена.
+	Subject string
+	// Method — apikey или jwt.
+	Method string
+	Scopes []Scope
+}
+
+const (
+	MethodAPIKey = "apikey"
+	MethodJWT    = "jwt"
+)
+
+func (p *Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext возвращает клиента, аутентифицированного middleware или перехватчиком.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
@@ -0,0 +1,26 @@
+package config
+
+import "time"
+
+// AuthConfig — аутентификация REST и gRPC. Клиент передаёт статический
+// API-ключ или JWT; должен быть задан хотя бы один источник.
+type AuthConfig struct {
+	Enabled bool
+
+	// APIKeysFile — JSON-массив ключей с их областями доступа.
+	APIKeysFile string
+
+	// JWKSFile или JWKSURL — ключи для проверки подписи JWT.
+	JWKSFile string
+	JWKSURL  string
+	// JWKSRefresh — как часто перечитывать JWKSURL. Неизвестный kid вызывает
+	// внеочередное обновление, но не чаще раза в несколько секунд.
+	JWKSRefresh time.Duration
+	JWKSTimeout time.Duration
+	// JWTIssuer и JWTAudience проверяются, если заданы.
+	JWTIssuer   string
+	JWTAudience string
+
+	// MetricsPublic оставляет /metrics без аутентификации.
+	MetricsPublic bool
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// TopicBinding описывает обработку одного входного топика или группы топиков
+// по регулярному выражению. Загружается из KAFKA_BINDINGS_FILE (JSON-массив);
+// без файла используется одна привязка для KAFKA_TOPIC.
+type TopicBinding struct {
+	Topic      string `json:"topic"`
+	TopicRegex string `json:"topic_regex"`
+	// Codec принудительно задаёт формат сообщений; пустое значение — по заголовку content-type.
+	Codec      string               `json:"codec"`
+	Validation *ValidationOverrides `json:"validation"`
+	// Aggregators — вычисляемые агрегаты, по умолчанию только max.
+	Aggregators []string `json:"aggregators"`
+	// Tag сохраняется вместе с агрегатами и отличает источники данных.
+	Tag string `json:"tag"`
+	// TopicConfig переопределяет параметры KAFKA_TOPIC_* для создаваемого топика.
+	TopicConfig *TopicOverrides `json:"topic_config"`
+}
+
+// DefaultTag — тег привязки, для которой он не задан.
+const DefaultTag = "default"
+
+// ValidationOverrides переопределяет заданные поля глобальной ValidationConfig.
+type ValidationOverrides struct {
+	RequiredFields []string  `json:"required_fields"`
+	UUIDFormat     *bool     `json:"uuid_format"`
+	MaxValues      *int      `json:"max_values"`
+	MaxPast        *Duration `json:"max_past"`
+	MaxFuture      *Duration `json:"max_future"`
+}
+
+func (o *ValidationOverrides) Apply(base ValidationConfig) ValidationConfig {
+	if o == nil {
+		return base
+	}
+	if o.RequiredFields != nil {
+		base.RequiredFields = o.RequiredFields
+	}
+	if o.UUIDFormat != nil {
+		base.UUIDFormat = *o.UUIDFormat
+	}
+	if o.MaxValues != nil {
+		base.MaxValues = *o.MaxValues
+	}
+	if o.MaxPast != nil {
+		base.MaxPast = time.Duration(*o.MaxPast)
+	}
+	if o.MaxFuture != nil {
+		base.MaxFuture = time.Duration(*o.MaxFuture)
+	}
+	return base
+}
+
+// TopicOverrides переопределяет заданные поля KafkaTopicConfig.
+// Configs заменяет настройки целиком.
+type TopicOverrides struct {
+	Partitions        *int              `json:"partitions"`
+	ReplicationFactor *int              `json:"replication_factor"`
+	Configs           map[string]string `json:"configs"`
+	ExpandPartitions  *bool             `json:"expand_partitions"`
+}
+
+func (o *TopicOverrides) Apply(base KafkaTopicConfig) KafkaTopicConfig {
+	if o == nil {
+		return base
+	}
+	if o.Partitions != nil {
+		base.Partitions = *o.Partitions
+	}
+	if o.ReplicationFactor != nil {
+		base.ReplicationFactor = *o.ReplicationFactor
+	}
+	if o.Configs != nil {
+		base.Configs = o.Configs
+	}
+	if o.ExpandPartitions != nil {
+		base.ExpandPartitions = *o.ExpandPartitions
+	}
+	return base
+}
+
+// Duration читается из JSON в формате time.ParseDuration, например "24h".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Bindings возвращает привязки из BindingsFile или одну привязку для Topic.
+func (k KafkaConfig) Bindings() ([]TopicBinding, error) {
+	if k.BindingsFile == "" {
+		return []TopicBinding{{Topic: k.Topic, Tag: DefaultTag}}, nil
+	}
+
+	data, err := os.ReadFile(k.BindingsFile)
+	if err != nil {
+		return nil, fmt.Errorf("read bindings file: %w", err)
+	}
+
+	var bindings []TopicBinding
+	if err := json.Unmarshal(data, &bindings); err != nil {
+		return nil, fmt.Errorf("parse bindings file: %w", err)
+	}
+	if len(bindings) == 0 {
+		return nil, errors.New("bindings file has no bindings")
+	}
+
+	for i := range bindings {
+		b := &bindings[i]
+		if (b.Topic == "") == (b.TopicRegex == "") {
+			return nil, fmt.Errorf("binding %d: exactly one of topic and topic_regex must be set", i)
+		}
+		if b.TopicConfig != nil && b.Topic == "" {
+			return nil, fmt.Errorf("binding %d: topic_config requires topic", i)
+		}
+		if b.Tag == "" {
+			b.Tag = DefaultTag
+		}
+	}
+	return bindings, nil
+}
@@ -9,11 +9,19 @@ import (
 )
 
 type Config struct {
-	Env      string
-	HTTP     HTTPConfig
-	GRPC     GRPCConfig
-	Postgres PostgresConfig
-	Kafka    KafkaConfig
+	Env        string
+	HTTP       HTTPConfig
+	GRPC       GRPCConfig
+	Postgres   PostgresConfig
+	Kafka      KafkaConfig
+	Validation ValidationConfig
+
+	SchemaRegistry SchemaRegistryConfig
+	Outbox         OutboxConfig
+	Tracing        TracingConfig
+	Auth           AuthConfig
+	RateLimit      RateLimitConfig
+	Replay         ReplayConfig
 
 	Workers  int
 	Interval time.Duration
@@ -24,11 +32,16 @@ func Load() *Config {
 		Env: getEnv("ENV", "local"),
 
 		HTTP: HTTPConfig{
-			Port: getEnv("HTTP_PORT", "8080"),
+			Port:           getEnv("HTTP_PORT", "8080"),
+			TLS:            loadServerTLS("HTTP"),
+			TrustedProxies: parsePrefixes(getEnv("HTTP_TRUSTED_PROXIES", "")),
 		},
 
 		GRPC: GRPCConfig{
-			Port: getEnv("GRPC_PORT", "9090"),
+			Port:           getEnv("GRPC_PORT", "9090"),
+			TLS:            loadServerTLS("GRPC"),
+			DefaultTimeout: getEnvDuration("GRPC_DEFAULT_TIMEOUT", "30s"),
+			MethodTimeouts: parseDurationMap(getEnv("GRPC_METHOD_TIMEOUTS", "")),
 		},
 
 		Postgres: PostgresConfig{
@@ -38,12 +51,108 @@ func Load() *Config {
 			Password: getEnv("POSTGRES_PASSWORD", "postgres"),
 			DBName:   getEnv("POSTGRES_DB", "agg"),
 			SSLMode:  getEnv("POSTGRES_SSL", "disable"),
+
+			ConflictPolicy: getEnv("POSTGRES_CONFLICT_POLICY", "latest_ts"),
 		},
 
 		Kafka: KafkaConfig{
 			Brokers: parseList(getEnv("KAFKA_BROKERS", "kafka:9092")),
 			Topic:   getEnv("KAFKA_TOPIC", "records"),
 			Group:   getEnv("KAFKA_GROUP", "agg-workers"),
+			Codec:   getEnv("KAFKA_CODEC", "json"),
+
+			ProducerID: getEnv("KAFKA_PRODUCER_ID", ""),
+
+			BindingsFile: getEnv("KAFKA_BINDINGS_FILE", ""),
+
+			RejectedTopic: getEnv("KAFKA_REJECTED_TOPIC", "records.rejected"),
+			MaxInFlight:   getEnvInt("KAFKA_MAX_IN_FLIGHT", 1000),
+			MaxAttempts:   getEnvInt("KAFKA_MAX_ATTEMPTS", 10),
+
+			ResultsTopic: getEnv("KAFKA_RESULTS_TOPIC", ""),
+
+			TopicSpec: loadTopicSpec("KAFKA_TOPIC", 10),
+			// Отклонённых записей мало, порядок в них не важен.
+			RejectedTopicSpec: loadTopicSpec("KAFKA_REJECTED_TOPIC", 1),
+			ResultsTopicSpec:  loadTopicSpec("KAFKA_RESULTS_TOPIC", 10),
+
+			ExactlyOnce:     getEnvBool("KAFKA_EXACTLY_ONCE", false),
+			TransactionalID: getEnv("KAFKA_TRANSACTIONAL_ID", ""),
+
+			ConsumerMaxRestarts:    getEnvInt("KAFKA_CONSUMER_MAX_RESTARTS", 3),
+			ConsumerRestartBackoff: getEnvDuration("KAFKA_CONSUMER_RESTART_BACKOFF", "5s"),
+
+			TLS: KafkaTLSConfig{
+				Enabled:            getEnvBool("KAFKA_TLS_ENABLED", false),
+				CAFile:             getEnv("KAFKA_TLS_CA_FILE", ""),
+				CertFile:           getEnv("KAFKA_TLS_CERT_FILE", ""),
+				KeyFile:            getEnv("KAFKA_TLS_KEY_FILE", ""),
+				ServerName:         getEnv("KAFKA_TLS_SERVER_NAME", ""),
+				InsecureSkipVerify: getEnvBool("KAFKA_TLS_INSECURE_SKIP_VERIFY", false),
+			},
+
+			SASL: KafkaSASLConfig{
+				Mechanism: getEnv("KAFKA_SASL_MECHANISM", ""),
+				Username:  getEnv("KAFKA_SASL_USERNAME", ""),
+				Password:  getEnv("KAFKA_SASL_PASSWORD", ""),
+			},
+		},
+
+		SchemaRegistry: SchemaRegistryConfig{
+			URL:      getEnv("SCHEMA_REGISTRY_URL", ""),
+			Username: getEnv("SCHEMA_REGISTRY_USERNAME", ""),
+			Password: getEnv("SCHEMA_REGISTRY_PASSWORD", ""),
+			File:     getEnv("SCHEMA_REGISTRY_FILE", ""),
+			Timeout:  getEnvDuration("SCHEMA_REGISTRY_TIMEOUT", "5s"),
+		},
+
+		Outbox: OutboxConfig{
+			PollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", "500ms"),
+			BatchSize:    getEnvInt("OUTBOX_BATCH_SIZE", 500),
+		},
+
+		Tracing: TracingConfig{
+			Exporter:     getEnv("TRACING_EXPORTER", "none"),
+			ServiceName:  getEnv("TRACING_SERVICE_NAME", "aggregator-service"),
+			OTLPEndpoint: getEnv("TRACING_OTLP_ENDPOINT", "otel-collector:4317"),
+			OTLPInsecure: getEnvBool("TRACING_OTLP_INSECURE", true),
+			File:         getEnv("TRACING_FILE", "traces.jsonl"),
+			SampleRatio:  getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		},
+
+		Auth: AuthConfig{
+			Enabled:       getEnvBool("AUTH_ENABLED", false),
+			APIKeysFile:   getEnv("AUTH_API_KEYS_FILE", ""),
+			JWKSFile:      getEnv("AUTH_JWKS_FILE", ""),
+			JWKSURL:       getEnv("AUTH_JWKS_URL", ""),
+			JWKSRefresh:   getEnvDuration("AUTH_JWKS_REFRESH", "5m"),
+			JWKSTimeout:   getEnvDuration("AUTH_JWKS_TIMEOUT", "5s"),
+			JWTIssuer:     getEnv("AUTH_JWT_ISSUER", ""),
+			JWTAudience:   getEnv("AUTH_JWT_AUDIENCE", ""),
+			MetricsPublic: getEnvBool("AUTH_METRICS_PUBLIC", false),
+		},
+
+		RateLimit: RateLimitConfig{
+			Enabled:     getEnvBool("RATE_LIMIT_ENABLED", false),
+			RPS:         getEnvFloat("RATE_LIMIT_RPS", 50),
+			Burst:       getEnvInt("RATE_LIMIT_BURST", 100),
+			PeriodRPS:   getEnvFloat("RATE_LIMIT_PERIOD_RPS", 1),
+			PeriodBurst: getEnvInt("RATE_LIMIT_PERIOD_BURST", 5),
+			IdleTTL:     getEnvDuration("RATE_LIMIT_IDLE_TTL", "10m"),
+			MaxClients:  getEnvInt("RATE_LIMIT_MAX_CLIENTS", 100000),
+		},
+
+		Replay: ReplayConfig{
+			MaxConcurrent: getEnvInt("REPLAY_MAX_CONCURRENT", 2),
+			Retention:     getEnvDuration("REPLAY_RETENTION", "1h"),
+		},
+
+		Validation: ValidationConfig{
+			RequiredFields: parseList(getEnv("VALIDATION_REQUIRED_FIELDS", "uuid,timestamp,value")),
+			UUIDFormat:     getEnvBool("VALIDATION_UUID_FORMAT", true),
+			MaxValues:      getEnvInt("VALIDATION_MAX_VALUES", 10000),
+			MaxPast:        getEnvDuration("VALIDATION_MAX_PAST", "0s"),
+			MaxFuture:      getEnvDuration("VALIDATION_MAX_FUTURE", "1m"),
 		},
 
 		Workers:  getEnvInt("WORKERS", 5),
@@ -70,6 +179,30 @@ func getEnvInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+func getEnvFloat(key string, defaultVal float64) float64 {
+	if val, ok := os.LookupEnv(key); ok {
+		parsed, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			log.Printf("invalid float for %s: %s, using default %g", key, val, defaultVal)
+			return defaultVal
+		}
+		return parsed
+	}
+	return defaultVal
+}
+
+func getEnvBool(key string, defaultVal bool) bool {
+	if val, ok := os.LookupEnv(key); ok {
+		parsed, err := strconv.ParseBool(val)
+		if err != nil {
+			log.Printf("invalid bool for %s: %s, using default %t", key, val, defaultVal)
+			return defaultVal
+		}
+		return parsed
+	}
+	return defaultVal
+}
+
 func getEnvDuration(key string, defaultVal string) time.Duration {
 	if val, ok := os.LookupEnv(key); ok {
 		d, err := time.ParseDuration(val)
@@ -95,3 +228,31 @@ func parseList(s string) []string {
 	}
 	return out
 }
+
+// parseMap разбирает список пар key=value через запятую.
+func parseMap(s string) map[string]string {
+	out := make(map[string]string)
+	for _, p := range parseList(s) {
+		key, value, ok := strings.Cut(p, "=")
+		if !ok {
+			log.Printf("invalid key=value pair %q, skipping", p)
+			continue
+		}
+		out[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return out
+}
+
+// parseDurationMap разбирает список пар key=duration через запятую.
+func parseDurationMap(s string) map[string]time.Duration {
+	out := make(map[string]time.Duration)
+	for key, value := range parseMap(s) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			log.Printf("invalid duration for %s: %s, skipping", key, value)
+			continue
+		}
+		out[key] = d
+	}
+	return out
+}
@@ -1,9 +1,27 @@
 package config
 
+import "time"
+
 type GRPCConfig struct {
 	Port string
+	TLS  ServerTLSConfig
+
+	// DefaultTimeout ограничивает unary-вызовы без более короткого дедлайна
+	// от клиента; 0 — без ограничения.
+	DefaultTimeout time.Duration
+	// MethodTimeouts — дедлайны отдельных методов по полному имени,
+	// например /aggregator.AdminService/StartReplay.
+	MethodTimeouts map[string]time.Duration
 }
 
 func (c GRPCConfig) Addr() string {
 	return ":" + c.Port
 }
+
+// Timeout возвращает дедлайн метода или DefaultTimeout.
+func (c GRPCConfig) Timeout(method string) time.Duration {
+	if d, ok := c.MethodTimeouts[method]; ok {
+		return d
+	}
+	return c.DefaultTimeout
+}
@@ -1,9 +1,37 @@
 package config
 
+import (
+	"log"
+	"net/netip"
+)
+
 type HTTPConfig struct {
 	Port string
+	TLS  ServerTLSConfig
+
+	// TrustedProxies — адреса и подсети прокси, которым разрешено передавать
+	// адрес клиента в X-Forwarded-For и X-Real-IP. От остальных заголовки игнорируются.
+	TrustedProxies []netip.Prefix
 }
 
 func (c HTTPConfig) Addr() string {
 	return ":" + c.Port
 }
+
+// parsePrefixes разбирает список подсетей (10.0.0.0/8) и отдельных адресов через запятую.
+func parsePrefixes(s string) []netip.Prefix {
+	var out []netip.Prefix
+	for _, p := range parseList(s) {
+		if addr, err := netip.ParseAddr(p); err == nil {
+			out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			log.Printf("invalid address or subnet %q, skipping", p)
+			continue
+		}
+		out = append(out, prefix.Masked())
+	}
+	return out
+}
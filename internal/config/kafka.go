@@ -1,7 +1,113 @@
 package config
 
+import "time"
+
 type KafkaConfig struct {
 	Brokers []string
 	Topic   string
 	Group   string
+	// BindingsFile — JSON с привязками входных топиков (см. TopicBinding).
+	// Если задан, Topic используется только для генератора и повторной обработки.
+	BindingsFile string
+	// Codec — формат, в котором продюсер пишет сообщения: json или protobuf.
+	// Консьюмер читает все поддерживаемые форматы по заголовку content-type.
+	Codec string
+	// ProducerID записывается в заголовок producer-id сообщений продюсера;
+	// по умолчанию — имя хоста.
+	ProducerID string
+
+	// RejectedTopic — топик для записей, не прошедших декодирование или валидацию.
+	// Пустое значение отключает перенаправление.
+	RejectedTopic string
+
+	// ResultsTopic — топик, в который публикуются сохранённые агрегаты.
+	// Пустое значение отключает публикацию.
+	ResultsTopic string
+
+	// ExactlyOnce включает транзакционный режим: чтение входного топика, публикация
+	// в ResultsTopic и коммит офсетов выполняются атомарно. TransactionalID должен
+	// быть уникален для каждого экземпляра; по умолчанию строится из группы и имени хоста.
+	ExactlyOnce     bool
+	TransactionalID string
+
+	// TopicSpec — параметры входного топика Topic. Топики привязок берут их
+	// за основу и переопределяют через topic_config.
+	TopicSpec KafkaTopicConfig
+	// RejectedTopicSpec и ResultsTopicSpec — параметры RejectedTopic и ResultsTopic.
+	RejectedTopicSpec KafkaTopicConfig
+	ResultsTopicSpec  KafkaTopicConfig
+
+	// MaxInFlight ограничивает число полученных, но ещё не обработанных записей.
+	MaxInFlight int
+	// MaxAttempts — сколько раз обрабатывается запись, которую не удаётся сохранить,
+	// прежде чем она уходит в RejectedTopic; 0 — без ограничения.
+	MaxAttempts int
+
+	// ConsumerMaxRestarts — сколько раз приложение пересоздаёт консьюмер после
+	// фатальной ошибки, прежде чем завершить процесс.
+	ConsumerMaxRestarts    int
+	ConsumerRestartBackoff time.Duration
+
+	TLS  KafkaTLSConfig
+	SASL KafkaSASLConfig
+}
+
+// KafkaTopicConfig описывает желаемое состояние топика. Существующий топик
+// сверяется с ним: расхождения логируются, а недостающие партиции добавляются,
+// если включён ExpandPartitions. Уменьшить число партиций Kafka не позволяет.
+type KafkaTopicConfig struct {
+	Partitions int
+	// ReplicationFactor -1 — значение брокера по умолчанию.
+	ReplicationFactor int
+	// Configs — настройки топика, например retention.ms, cleanup.policy,
+	// min.insync.replicas.
+	Configs          map[string]string
+	ExpandPartitions bool
+}
+
+// loadTopicSpec читает параметры топика из переменных <prefix>_PARTITIONS,
+// <prefix>_REPLICATION_FACTOR, <prefix>_CONFIGS и <prefix>_EXPAND_PARTITIONS.
+func loadTopicSpec(prefix string, partitions int) KafkaTopicConfig {
+	return KafkaTopicConfig{
+		Partitions:        getEnvInt(prefix+"_PARTITIONS", partitions),
+		ReplicationFactor: getEnvInt(prefix+"_REPLICATION_FACTOR", 1),
+		Configs:           parseMap(getEnv(prefix+"_CONFIGS", "")),
+		ExpandPartitions:  getEnvBool(prefix+"_EXPAND_PARTITIONS", false),
+	}
+}
+
+// TopicSpecs возвращает топики, которые сервис создаёт при запуске, с параметрами
+// каждого: входной топик, явно указанные топики привязок, RejectedTopic и ResultsTopic.
+func (k KafkaConfig) TopicSpecs(bindings []TopicBinding) map[string]KafkaTopicConfig {
+	specs := map[string]KafkaTopicConfig{k.Topic: k.TopicSpec}
+	for _, b := range bindings {
+		if b.Topic != "" {
+			specs[b.Topic] = b.TopicConfig.Apply(k.TopicSpec)
+		}
+	}
+	if k.RejectedTopic != "" {
+		specs[k.RejectedTopic] = k.RejectedTopicSpec
+	}
+	if k.ResultsTopic != "" {
+		specs[k.ResultsTopic] = k.ResultsTopicSpec
+	}
+	return specs
+}
+
+type KafkaTLSConfig struct {
+	Enabled bool
+	// CAFile — PEM с корневыми сертификатами; если не задан, используются системные.
+	CAFile string
+	// CertFile и KeyFile задают клиентский сертификат для mTLS.
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+type KafkaSASLConfig struct {
+	// Mechanism — PLAIN, SCRAM-SHA-256 или SCRAM-SHA-512; пустое значение отключает SASL.
+	Mechanism string
+	Username  string
+	Password  string
 }
@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+// OutboxConfig задаёт публикацию результатов из таблицы outbox в KAFKA_RESULTS_TOPIC.
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+}
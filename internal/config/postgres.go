@@ -9,6 +9,8 @@ type PostgresConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	ConflictPolicy string
 }
 
 func (p PostgresConfig) DSN() string {
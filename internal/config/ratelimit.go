@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+// RateLimitConfig — token bucket на клиента: аутентифицированные запросы
+// считаются по ключу или субъекту токена, остальные — по IP.
+type RateLimitConfig struct {
+	Enabled bool
+
+	// RPS и Burst — скорость пополнения и ёмкость корзины для обычных запросов.
+	RPS   float64
+	Burst int
+
+	// PeriodRPS и PeriodBurst — отдельная корзина для запросов за период
+	// (GET /max?from=&to=, GetMax без uuid), которые сканируют много строк.
+	PeriodRPS   float64
+	PeriodBurst int
+
+	// IdleTTL — через сколько без запросов корзина клиента удаляется.
+	IdleTTL time.Duration
+
+	// MaxClients ограничивает число корзин каждого класса; 0 — без ограничения.
+	MaxClients int
+}
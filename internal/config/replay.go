@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// ReplayConfig ограничивает повторные обработки, запущенные через админский API.
+type ReplayConfig struct {
+	// MaxConcurrent — сколько повторных обработок может идти одновременно;
+	// следующие запуски отклоняются, пока одна из них не завершится.
+	MaxConcurrent int
+
+	// Retention — сколько завершённая задача остаётся доступной по id.
+	Retention time.Duration
+}
@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+// SchemaRegistryConfig задаёт источник схем для сообщений в формате Confluent.
+// File имеет приоритет над URL; если не задано ни то ни другое, такие сообщения отклоняются.
+type SchemaRegistryConfig struct {
+	URL      string
+	Username string
+	Password string
+	File     string
+	Timeout  time.Duration
+}
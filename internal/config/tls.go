@@ -0,0 +1,36 @@
+package config
+
+import "time"
+
+// ServerTLSConfig — TLS для REST или gRPC сервера.
+type ServerTLSConfig struct {
+	Enabled  bool
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile — PEM с CA для проверки клиентских сертификатов (mTLS).
+	ClientCAFile string
+	// ClientAuth — none, optional или require. Пустое значение означает require,
+	// если задан ClientCAFile, и none в остальных случаях.
+	ClientAuth string
+
+	// MinVersion — 1.2 или 1.3.
+	MinVersion string
+
+	// ReloadInterval — как часто проверять, изменились ли файлы сертификатов.
+	// Новые файлы применяются к следующим соединениям без перезапуска.
+	ReloadInterval time.Duration
+}
+
+// loadServerTLS читает параметры с префиксом, например HTTP_TLS_CERT_FILE.
+func loadServerTLS(prefix string) ServerTLSConfig {
+	return ServerTLSConfig{
+		Enabled:        getEnvBool(prefix+"_TLS_ENABLED", false),
+		CertFile:       getEnv(prefix+"_TLS_CERT_FILE", ""),
+		KeyFile:        getEnv(prefix+"_TLS_KEY_FILE", ""),
+		ClientCAFile:   getEnv(prefix+"_TLS_CLIENT_CA_FILE", ""),
+		ClientAuth:     getEnv(prefix+"_TLS_CLIENT_AUTH", ""),
+		MinVersion:     getEnv(prefix+"_TLS_MIN_VERSION", "1.2"),
+		ReloadInterval: getEnvDuration(prefix+"_TLS_RELOAD_INTERVAL", "10s"),
+	}
+}
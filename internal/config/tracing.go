@@ -0,0 +1,19 @@
+package config
+
+type TracingConfig struct {
+	// Exporter — otlp, stdout, file или none. При none спаны не экспортируются,
+	// но идентификаторы трассировки всё равно создаются и попадают в логи и заголовки.
+	Exporter    string
+	ServiceName string
+
+	// OTLPEndpoint — адрес OTLP/gRPC коллектора, например otel-collector:4317.
+	OTLPEndpoint string
+	OTLPInsecure bool
+
+	// File — путь для экспортёра file, спаны пишутся построчно в JSON.
+	File string
+
+	// SampleRatio — доля новых трассировок, которые экспортируются (0..1).
+	// Решение вызывающей стороны, переданное в traceparent, соблюдается.
+	SampleRatio float64
+}
@@ -0,0 +1,13 @@
+package config
+
+import "time"
+
+type ValidationConfig struct {
+	// RequiredFields — поля ValueRecord, которые должны быть заполнены: uuid, timestamp, value.
+	RequiredFields []string
+	UUIDFormat     bool
+	MaxValues      int
+	// MaxPast и MaxFuture ограничивают отклонение timestamp от текущего времени, 0 — без ограничения.
+	MaxPast   time.Duration
+	MaxFuture time.Duration
+}
@@ -0,0 +1,106 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Admin выполняет административные запросы к кластеру от имени сервиса.
+type Admin struct {
+	client *kgo.Client
+	adm    *kadm.Client
+	group  string
+}
+
+func NewAdmin(common []kgo.Opt, group string) (*Admin, error) {
+	client, err := newClient(common, "admin")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Admin{
+		client: client,
+		adm:    kadm.NewClient(client),
+		group:  group,
+	}, nil
+}
+
+// GroupStatus возвращает состояние consumer group сервиса:
+// участников, назначенные им партиции и отставание по каждой партиции.
+func (a *Admin) GroupStatus(ctx context.Context) (*model.ConsumerGroupStatus, error) {
+	const op = "kafka.Admin.GroupStatus"
+
+	lags, err := a.adm.Lag(ctx, a.group)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	described, ok := lags[a.group]
+	if !ok {
+		return nil, fmt.Errorf("%s: group %q not described", op, a.group)
+	}
+	if err := described.Error(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return groupStatus(described), nil
+}
+
+// groupStatus переводит ответ kadm в модель. Отставание партиций с ошибкой
+// (kadm отдаёт для них -1) в общую сумму не входит.
+func groupStatus(described kadm.DescribedGroupLag) *model.ConsumerGroupStatus {
+	status := &model.ConsumerGroupStatus{
+		Group:    described.Group,
+		State:    described.State,
+		Protocol: described.Protocol,
+	}
+
+	for _, m := range described.Members {
+		member := model.GroupMember{
+			MemberID:   m.MemberID,
+			ClientID:   m.ClientID,
+			ClientHost: m.ClientHost,
+		}
+		if m.InstanceID != nil {
+			member.InstanceID = *m.InstanceID
+		}
+		if assigned, ok := m.Assigned.AsConsumer(); ok {
+			for _, t := range assigned.Topics {
+				member.Assignments = append(member.Assignments, model.TopicAssignment{
+					Topic:      t.Topic,
+					Partitions: t.Partitions,
+				})
+			}
+		}
+		status.Members = append(status.Members, member)
+	}
+
+	for _, l := range described.Lag.Sorted() {
+		p := model.PartitionLag{
+			Topic:           l.Topic,
+			Partition:       l.Partition,
+			CommittedOffset: l.Commit.At,
+			HighWatermark:   l.End.Offset,
+			Lag:             l.Lag,
+		}
+		if l.Member != nil {
+			p.MemberID = l.Member.MemberID
+		}
+		if l.Err != nil {
+			p.Error = l.Err.Error()
+		} else {
+			status.TotalLag += l.Lag
+		}
+		status.Partitions = append(status.Partitions, p)
+	}
+
+	return status
+}
+
+func (a *Admin) Close() {
+	a.client.Close()
+}
@@ -0,0 +1,215 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+func TestGroupStatus(t *testing.T) {
+	instance := "node-1"
+	member := kadm.DescribedGroupMember{
+		MemberID:   "m-1",
+		InstanceID: &instance,
+		ClientID:   "aggregator-consumer-1",
+		ClientHost: "/10.0.0.1",
+	}
+
+	lag := func(partition int32, commit, end int64, m *kadm.DescribedGroupMember) kadm.GroupMemberLag {
+		return kadm.GroupMemberLag{
+			Member:    m,
+			Topic:     testTopic,
+			Partition: partition,
+			Commit:    kadm.Offset{Topic: testTopic, Partition: partition, At: commit},
+			End:       kadm.ListedOffset{Topic: testTopic, Partition: partition, Offset: end},
+			Lag:       end - commit,
+		}
+	}
+	failed := lag(2, -1, 7, &member)
+	failed.Lag = -1
+	failed.Err = kerr.UnknownTopicOrPartition
+
+	tests := []struct {
+		name      string
+		described kadm.DescribedGroupLag
+		want      *model.ConsumerGroupStatus
+	}{
+		{
+			name: "Stable",
+			described: kadm.DescribedGroupLag{
+				Group:    "aggregator",
+				State:    "Stable",
+				Protocol: "cooperative-sticky",
+				Members:  []kadm.DescribedGroupMember{member},
+				Lag: kadm.GroupLag{testTopic: {
+					1: lag(1, 5, 8, &member),
+					0: lag(0, 10, 10, &member),
+				}},
+			},
+			want: &model.ConsumerGroupStatus{
+				Group:    "aggregator",
+				State:    "Stable",
+				Protocol: "cooperative-sticky",
+				Members: []model.GroupMember{{
+					MemberID:   "m-1",
+					InstanceID: "node-1",
+					ClientID:   "aggregator-consumer-1",
+					ClientHost: "/10.0.0.1",
+				}},
+				Partitions: []model.PartitionLag{
+					{Topic: testTopic, Partition: 0, CommittedOffset: 10, HighWatermark: 10, MemberID: "m-1"},
+					{Topic: testTopic, Partition: 1, CommittedOffset: 5, HighWatermark: 8, Lag: 3, MemberID: "m-1"},
+				},
+				TotalLag: 3,
+			},
+		},
+		{
+			name: "Empty group keeps committed lag without members",
+			described: kadm.DescribedGroupLag{
+				Group: "aggregator",
+				State: "Empty",
+				Lag:   kadm.GroupLag{testTopic: {0: lag(0, 4, 9, nil)}},
+			},
+			want: &model.ConsumerGroupStatus{
+				Group: "aggregator",
+				State: "Empty",
+				Partitions: []model.PartitionLag{
+					{Topic: testTopic, Partition: 0, CommittedOffset: 4, HighWatermark: 9, Lag: 5},
+				},
+				TotalLag: 5,
+			},
+		},
+		{
+			name: "Rebalance excludes failed partitions from total",
+			described: kadm.DescribedGroupLag{
+				Group:    "aggregator",
+				State:    "PreparingRebalance",
+				Protocol: "cooperative-sticky",
+				Members:  []kadm.DescribedGroupMember{member},
+				Lag: kadm.GroupLag{testTopic: {
+					0: lag(0, 0, 6, &member),
+					2: failed,
+				}},
+			},
+			want: &model.ConsumerGroupStatus{
+				Group:    "aggregator",
+				State:    "PreparingRebalance",
+				Protocol: "cooperative-sticky",
+				Members: []model.GroupMember{{
+					MemberID:   "m-1",
+					InstanceID: "node-1",
+					ClientID:   "aggregator-consumer-1",
+					ClientHost: "/10.0.0.1",
+				}},
+				Partitions: []model.PartitionLag{
+					{Topic: testTopic, Partition: 0, CommittedOffset: 0, HighWatermark: 6, Lag: 6, MemberID: "m-1"},
+					{
+						Topic: testTopic, Partition: 2, CommittedOffset: -1, HighWatermark: 7, Lag: -1,
+						MemberID: "m-1", Error: kerr.UnknownTopicOrPartition.Error(),
+					},
+				},
+				TotalLag: 6,
+			},
+		},
+		{
+			name: "CompletingRebalance",
+			described: kadm.DescribedGroupLag{
+				Group:    "aggregator",
+				State:    "CompletingRebalance",
+				Protocol: "cooperative-sticky",
+			},
+			want: &model.ConsumerGroupStatus{
+				Group:    "aggregator",
+				State:    "CompletingRebalance",
+				Protocol: "cooperative-sticky",
+			},
+		},
+		{
+			name:      "Dead",
+			described: kadm.DescribedGroupLag{Group: "aggregator", State: "Dead"},
+			want:      &model.ConsumerGroupStatus{Group: "aggregator", State: "Dead"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, groupStatus(tt.described))
+		})
+	}
+}
+
+func TestAdmin_GroupStatus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(2, testTopic))
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+	common := []kgo.Opt{kgo.SeedBrokers(cluster.ListenAddrs()...)}
+
+	producer, err := kgo.NewClient(append(common, kgo.RecordPartitioner(kgo.ManualPartitioner()))...)
+	require.NoError(t, err)
+	t.Cleanup(producer.Close)
+	for i := range 3 {
+		rec := &kgo.Record{Topic: testTopic, Partition: int32(i % 2), Value: []byte("v")}
+		require.NoError(t, producer.ProduceSync(ctx, rec).FirstErr())
+	}
+
+	consumer, err := kgo.NewClient(append(common,
+		kgo.ConsumerGroup("aggregator"),
+		kgo.ConsumeTopics(testTopic),
+		kgo.DisableAutoCommit(),
+	)...)
+	require.NoError(t, err)
+	t.Cleanup(consumer.Close)
+
+	// Коммитится только первая запись партиции 0, остальное остаётся отставанием.
+	var committed bool
+	for !committed {
+		fetches := consumer.PollFetches(ctx)
+		require.NoError(t, fetches.Err())
+		fetches.EachRecord(func(r *kgo.Record) {
+			if r.Partition == 0 && r.Offset == 0 {
+				require.NoError(t, consumer.CommitRecords(ctx, r))
+				committed = true
+			}
+		})
+	}
+
+	admin, err := NewAdmin(common, "aggregator")
+	require.NoError(t, err)
+	t.Cleanup(admin.Close)
+
+	status, err := admin.GroupStatus(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Stable", status.State)
+	require.Len(t, status.Members, 1)
+	require.Len(t, status.Members[0].Assignments, 1)
+	assert.Equal(t, testTopic, status.Members[0].Assignments[0].Topic)
+	assert.ElementsMatch(t, []int32{0, 1}, status.Members[0].Assignments[0].Partitions)
+
+	require.Len(t, status.Partitions, 2)
+	assert.Equal(t, int64(1), status.Partitions[0].CommittedOffset)
+	assert.Equal(t, int64(1), status.Partitions[0].Lag)
+	assert.Equal(t, status.Members[0].MemberID, status.Partitions[0].MemberID)
+	assert.Equal(t, int64(2), status.TotalLag)
+
+	t.Run("Unknown group", func(t *testing.T) {
+		other, err := NewAdmin(common, "missing")
+		require.NoError(t, err)
+		t.Cleanup(other.Close)
+
+		_, err = other.GroupStatus(ctx)
+		assert.ErrorIs(t, err, kerr.GroupIDNotFound)
+	})
+}
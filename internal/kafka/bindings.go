@@ -0,0 +1,192 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/validation"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// reasonUnboundTopic — запись из топика, которому не соответствует ни одна
+// привязка, например созданного после запуска под слишком широкое выражение.
+const reasonUnboundTopic = "unbound_topic"
+
+// binding — настройки обработки записей одного топика.
+type binding struct {
+	topic     string
+	regex     *regexp.Regexp
+	codecs    *CodecRegistry
+	codec     Codec // nil — выбор по заголовку content-type
+	validator *validation.Validator
+	// saveMax — max сохраняется в max_values. Только для тега по умолчанию:
+	// max_values хранит одно значение на uuid, и привязки с другими тегами
+	// перезаписывали бы его. У них max идёт в aggregates вместе с остальными.
+	saveMax bool
+	// aggregates — агрегаты, сохраняемые в таблицу aggregates с тегом привязки.
+	aggregates []string
+	tag        string
+}
+
+func (b *binding) decode(ctx context.Context, r *kgo.Record) (model.ValueRecord, error) {
+	if b.codec != nil {
+		return b.codec.Decode(r.Value)
+	}
+	return b.codecs.Decode(ctx, r)
+}
+
+// Bindings сопоставляет входные топики с их привязками. Топик, указанный явно,
+// важнее регулярных выражений; среди выражений выигрывает первое по порядку.
+type Bindings struct {
+	bindings []*binding
+
+	mu      sync.RWMutex
+	byTopic map[string]*binding
+}
+
+func NewBindings(cfgs []config.TopicBinding, validationCfg config.ValidationConfig, codecs *CodecRegistry) (*Bindings, error) {
+	b := &Bindings{byTopic: make(map[string]*binding)}
+
+	for i, cfg := range cfgs {
+		bnd := &binding{
+			topic:  cfg.Topic,
+			codecs: codecs,
+			tag:    cfg.Tag,
+		}
+		if bnd.tag == "" {
+			bnd.tag = config.DefaultTag
+		}
+
+		if cfg.TopicRegex != "" {
+			re, err := regexp.Compile(cfg.TopicRegex)
+			if err != nil {
+				return nil, fmt.Errorf("binding %d: topic regex: %w", i, err)
+			}
+			bnd.regex = re
+		}
+
+		if cfg.Codec != "" {
+			codec, err := codecs.ByName(cfg.Codec)
+			if err != nil {
+				return nil, fmt.Errorf("binding %d: %w", i, err)
+			}
+			bnd.codec = codec
+		}
+
+		validator, err := validation.New(cfg.Validation.Apply(validationCfg))
+		if err != nil {
+			return nil, fmt.Errorf("binding %d: %w", i, err)
+		}
+		bnd.validator = validator
+
+		aggregators := cfg.Aggregators
+		if len(aggregators) == 0 {
+			aggregators = []string{service.AggregatorMax}
+		}
+		for _, name := range aggregators {
+			if err := service.CheckAggregator(name); err != nil {
+				return nil, fmt.Errorf("binding %d: %w", i, err)
+			}
+			if name == service.AggregatorMax && bnd.tag == config.DefaultTag {
+				bnd.saveMax = true
+			} else if !slices.Contains(bnd.aggregates, name) {
+				bnd.aggregates = append(bnd.aggregates, name)
+			}
+		}
+
+		if bnd.topic != "" {
+			if _, ok := b.byTopic[bnd.topic]; ok {
+				return nil, fmt.Errorf("binding %d: topic %q is bound twice", i, bnd.topic)
+			}
+			b.byTopic[bnd.topic] = bnd
+		}
+		b.bindings = append(b.bindings, bnd)
+	}
+
+	return b, nil
+}
+
+// Topics возвращает явно указанные топики привязок.
+func (b *Bindings) Topics() []string {
+	var topics []string
+	for _, bnd := range b.bindings {
+		if bnd.topic != "" {
+			topics = append(topics, bnd.topic)
+		}
+	}
+	return topics
+}
+
+// consumeOpts подписывает клиента на топики привязок. Если есть хотя бы одно
+// выражение, все топики передаются как выражения: franz-go не смешивает
+// два вида подписки.
+func (b *Bindings) consumeOpts() []kgo.Opt {
+	hasRegex := slices.ContainsFunc(b.bindings, func(bnd *binding) bool { return bnd.regex != nil })
+	if !hasRegex {
+		return []kgo.Opt{kgo.ConsumeTopics(b.Topics()...)}
+	}
+
+	patterns := make([]string, 0, len(b.bindings))
+	for _, bnd := range b.bindings {
+		if bnd.regex != nil {
+			patterns = append(patterns, bnd.regex.String())
+		} else {
+			patterns = append(patterns, "^"+regexp.QuoteMeta(bnd.topic)+"$")
+		}
+	}
+	return []kgo.Opt{kgo.ConsumeTopics(patterns...), kgo.ConsumeRegex()}
+}
+
+// lookup возвращает привязку топика или nil. Результат сопоставления
+// с выражениями кешируется, топиков в подписке немного.
+func (b *Bindings) lookup(topic string) *binding {
+	b.mu.RLock()
+	bnd, ok := b.byTopic[topic]
+	b.mu.RUnlock()
+	if ok {
+		return bnd
+	}
+
+	bnd = b.match(topic)
+	b.mu.Lock()
+	b.byTopic[topic] = bnd
+	b.mu.Unlock()
+	return bnd
+}
+
+// match ищет привязку топика без кеширования: годится для топиков,
+// пришедших извне, которые могут и не относиться к подписке.
+func (b *Bindings) match(topic string) *binding {
+	for _, candidate := range b.bindings {
+		if candidate.topic == topic {
+			return candidate
+		}
+	}
+	for _, candidate := range b.bindings {
+		if candidate.regex != nil && candidate.regex.MatchString(topic) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// replayTopic проверяет топик повторной обработки. Пустой топик допустим,
+// только если привязка одна и задана явным топиком.
+func (b *Bindings) replayTopic(topic string) (string, error) {
+	if topic == "" {
+		if len(b.bindings) != 1 || b.bindings[0].topic == "" {
+			return "", service.ErrReplayTopicRequired
+		}
+		return b.bindings[0].topic, nil
+	}
+	if b.match(topic) == nil {
+		return "", fmt.Errorf("%w: %q", service.ErrUnknownReplayTopic, topic)
+	}
+	return topic, nil
+}
@@ -0,0 +1,138 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/service/mocks"
+)
+
+func TestNewBindings(t *testing.T) {
+	validationCfg := config.ValidationConfig{}
+
+	t.Run("Lookup", func(t *testing.T) {
+		bindings, err := NewBindings([]config.TopicBinding{
+			{TopicRegex: `^sensors\..+`, Tag: "sensors"},
+			{Topic: "sensors.legacy", Codec: "protobuf"},
+		}, validationCfg, DefaultCodecs())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"sensors.legacy"}, bindings.Topics())
+
+		legacy := bindings.lookup("sensors.legacy")
+		require.NotNil(t, legacy)
+		assert.Equal(t, config.DefaultTag, legacy.tag)
+		assert.Equal(t, "protobuf", legacy.codec.Name())
+
+		sensors := bindings.lookup("sensors.a")
+		require.NotNil(t, sensors)
+		assert.Equal(t, "sensors", sensors.tag)
+		assert.Nil(t, sensors.codec)
+		assert.False(t, sensors.saveMax, "max of a tagged binding must not overwrite max_values")
+		assert.Equal(t, []string{"max"}, sensors.aggregates)
+		assert.True(t, legacy.saveMax)
+
+		assert.Nil(t, bindings.lookup("other"))
+	})
+
+	t.Run("Aggregators", func(t *testing.T) {
+		bindings, err := NewBindings([]config.TopicBinding{
+			{Topic: "t", Aggregators: []string{"min", "sum", "min"}},
+		}, validationCfg, DefaultCodecs())
+		require.NoError(t, err)
+
+		bnd := bindings.lookup("t")
+		assert.False(t, bnd.saveMax)
+		assert.Equal(t, []string{"min", "sum"}, bnd.aggregates)
+	})
+
+	invalid := []struct {
+		name    string
+		binding config.TopicBinding
+	}{
+		{name: "Unknown aggregator", binding: config.TopicBinding{Topic: "t", Aggregators: []string{"median"}}},
+		{name: "Unknown codec", binding: config.TopicBinding{Topic: "t", Codec: "xml"}},
+		{name: "Invalid regex", binding: config.TopicBinding{TopicRegex: "("}},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewBindings([]config.TopicBinding{tc.binding}, validationCfg, DefaultCodecs())
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("Duplicate topic", func(t *testing.T) {
+		_, err := NewBindings([]config.TopicBinding{{Topic: "t"}, {Topic: "t"}}, validationCfg, DefaultCodecs())
+		assert.Error(t, err)
+	})
+}
+
+func TestFranzConsumer_Bindings(t *testing.T) {
+	const sensorsTopic = "sensors.a"
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, testTopic, sensorsTopic))
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+	common := []kgo.Opt{kgo.SeedBrokers(cluster.ListenAddrs()...)}
+
+	now := time.Now().UTC()
+	produceTopicRecords(t, common, testTopic, 0, testValueRecord(now))
+	produceTopicRecords(t, common, sensorsTopic, 0, testValueRecord(now))
+
+	var (
+		mu         sync.Mutex
+		maxSaved   []*model.MaxValueRecord
+		aggregates []*model.AggregateRecord
+	)
+	svc := service.New(logger, &mocks.MockMaxValueRepository{
+		SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			maxSaved = append(maxSaved, rec)
+			return &model.MaxValueResult{UUID: rec.UUID}, nil
+		},
+		SaveAggregatesFunc: func(ctx context.Context, rec *model.AggregateRecord) error {
+			mu.Lock()
+			defer mu.Unlock()
+			aggregates = append(aggregates, rec)
+			return nil
+		},
+	})
+
+	bindings := newTestBindings(t,
+		config.TopicBinding{Topic: testTopic},
+		config.TopicBinding{TopicRegex: `^sensors\.`, Aggregators: []string{"max", "min", "sum", "count"}, Tag: "sensors"},
+	)
+	cfg := config.KafkaConfig{Group: "test-group", MaxInFlight: 4}
+	c, err := NewConsumer(common, cfg, 2, svc, bindings, noop.NewTracerProvider(), logger)
+	require.NoError(t, err)
+	defer c.Close()
+	go func() { _ = c.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(maxSaved) == 1 && len(aggregates) == 1
+	}, 10*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, testTopic, maxSaved[0].Source.Topic)
+	assert.Equal(t, int64(3), maxSaved[0].MaxValue)
+	assert.Equal(t, "sensors", aggregates[0].Tag)
+	assert.Equal(t, map[string]int64{"max": 3, "min": 1, "sum": 6, "count": 3}, aggregates[0].Values)
+}
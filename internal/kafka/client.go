@@ -0,0 +1,134 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+	"github.com/twmb/franz-go/plugin/kprom"
+)
+
+// ClientOptions собирает общие для всех клиентов franz-go опции:
+// seed-брокеры, TLS, SASL и реестр метрик kprom (reg == nil выключает метрики).
+// Консьюмеры, продюсеры и админ-запросы добавляют к ним только собственные настройки.
+func ClientOptions(cfg config.KafkaConfig, reg prometheus.Registerer) ([]kgo.Opt, error) {
+	opts := []kgo.Opt{kgo.SeedBrokers(cfg.Brokers...)}
+	if reg != nil {
+		opts = append(opts, withMetrics(reg))
+	}
+
+	if cfg.TLS.Enabled {
+		tlsCfg, err := tlsConfig(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("kafka tls: %w", err)
+		}
+		opts = append(opts, kgo.DialTLSConfig(tlsCfg))
+	}
+
+	if cfg.SASL.Mechanism != "" {
+		mechanism, err := saslMechanism(cfg.SASL)
+		if err != nil {
+			return nil, fmt.Errorf("kafka sasl: %w", err)
+		}
+		opts = append(opts, kgo.SASL(mechanism))
+	}
+
+	return opts, nil
+}
+
+func tlsConfig(cfg config.KafkaTLSConfig) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+func saslMechanism(cfg config.KafkaSASLConfig) (sasl.Mechanism, error) {
+	if cfg.Username == "" {
+		return nil, errors.New("username is required")
+	}
+
+	switch strings.ToUpper(cfg.Mechanism) {
+	case "PLAIN":
+		return plain.Auth{User: cfg.Username, Pass: cfg.Password}.AsMechanism(), nil
+	case "SCRAM-SHA-256":
+		return scram.Auth{User: cfg.Username, Pass: cfg.Password}.AsSha256Mechanism(), nil
+	case "SCRAM-SHA-512":
+		return scram.Auth{User: cfg.Username, Pass: cfg.Password}.AsSha512Mechanism(), nil
+	default:
+		return nil, fmt.Errorf("unsupported mechanism %q", cfg.Mechanism)
+	}
+}
+
+// metricsOpt передаёт реестр kprom через общие опции клиентов. Сама по себе
+// она ничего не меняет (пустой WithHooks): хуки добавляет clientOpts.
+type metricsOpt struct {
+	kgo.Opt
+	reg prometheus.Registerer
+}
+
+func withMetrics(reg prometheus.Registerer) kgo.Opt {
+	return metricsOpt{Opt: kgo.WithHooks(), reg: reg}
+}
+
+var clientSeq atomic.Int64
+
+// newClient создаёт клиента с уникальным client id и, если в common есть реестр
+// (см. ClientOptions), хуками kprom. kprom.Metrics хранит коллекторы одного клиента,
+// поэтому у каждого клиента свой экземпляр, а client_id в метках разделяет их серии.
+// Коллекторы снимаются с реестра при закрытии клиента.
+func newClient(common []kgo.Opt, role string, opts ...kgo.Opt) (*kgo.Client, error) {
+	return kgo.NewClient(clientOpts(common, role, opts...)...)
+}
+
+// clientOpts собирает опции newClient для конструкторов, которые создают клиента
+// сами, например kgo.NewGroupTransactSession.
+func clientOpts(common []kgo.Opt, role string, opts ...kgo.Opt) []kgo.Opt {
+	clientID := fmt.Sprintf("aggregator-%s-%d", role, clientSeq.Add(1))
+
+	all := append(slices.Clone(common), kgo.ClientID(clientID))
+	for _, opt := range common {
+		if m, ok := opt.(metricsOpt); ok {
+			all = append(all, kgo.WithHooks(kprom.NewMetrics("kafka",
+				kprom.Registerer(m.reg),
+				kprom.WithClientLabel(),
+			)))
+			break
+		}
+	}
+	return append(all, opts...)
+}
@@ -0,0 +1,80 @@
+package kafka
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+)
+
+func TestSaslMechanism(t *testing.T) {
+	for _, name := range []string{"PLAIN", "SCRAM-SHA-256", "scram-sha-512"} {
+		t.Run(name, func(t *testing.T) {
+			m, err := saslMechanism(config.KafkaSASLConfig{Mechanism: name, Username: "user", Password: "pass"})
+			require.NoError(t, err)
+			assert.NotEmpty(t, m.Name())
+		})
+	}
+
+	t.Run("Unknown mechanism", func(t *testing.T) {
+		_, err := saslMechanism(config.KafkaSASLConfig{Mechanism: "GSSAPI", Username: "user"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Missing username", func(t *testing.T) {
+		_, err := saslMechanism(config.KafkaSASLConfig{Mechanism: "PLAIN"})
+		assert.Error(t, err)
+	})
+}
+
+func TestClientOptions(t *testing.T) {
+	cfg := config.KafkaConfig{
+		Brokers: []string{"a:9092", "b:9092"},
+		TLS:     config.KafkaTLSConfig{Enabled: true},
+		SASL:    config.KafkaSASLConfig{Mechanism: "PLAIN", Username: "user", Password: "pass"},
+	}
+	opts, err := ClientOptions(cfg, nil)
+	require.NoError(t, err)
+	assert.Len(t, opts, 3)
+
+	opts, err = ClientOptions(cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	assert.Len(t, opts, 4)
+
+	_, err = ClientOptions(config.KafkaConfig{
+		TLS: config.KafkaTLSConfig{Enabled: true, CAFile: "/nonexistent/ca.pem"},
+	}, nil)
+	assert.Error(t, err)
+}
+
+func TestNewClient_Metrics(t *testing.T) {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1))
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+
+	reg := prometheus.NewRegistry()
+	client, err := newClient([]kgo.Opt{kgo.SeedBrokers(cluster.ListenAddrs()...), withMetrics(reg)}, "metrics")
+	require.NoError(t, err)
+	t.Cleanup(client.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, client.Ping(ctx))
+
+	rec := httptest.NewRecorder()
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "kafka_connects_total")
+	assert.Contains(t, rec.Body.String(), `client_id="`+client.OptValue(kgo.ClientID).(string)+`"`)
+}
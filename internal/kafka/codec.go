@@ -0,0 +1,153 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "github.com/Pavel26ru/aggregator-service/gen"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/schemaregistry"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	ContentTypeHeader = "content-type"
+
+	ContentTypeJSONv1     = "application/vnd.aggregator.value-record.v1+json"
+	ContentTypeProtobufv1 = "application/vnd.aggregator.value-record.v1+protobuf"
+)
+
+// Codec кодирует ValueRecord в одну конкретную версию формата сообщения.
+type Codec interface {
+	Name() string
+	ContentType() string
+	Encode(rec model.ValueRecord) ([]byte, error)
+	Decode(data []byte) (model.ValueRecord, error)
+}
+
+// CodecRegistry выбирает Codec по заголовку content-type записи,
+// что позволяет читать несколько форматов одновременно во время миграций.
+// Записи без заголовка декодируются кодеком по умолчанию.
+type CodecRegistry struct {
+	byContentType map[string]Codec
+	byName        map[string]Codec
+	fallback      Codec
+	confluent     *schemaregistry.Deserializer
+}
+
+func NewCodecRegistry(fallback Codec, codecs ...Codec) *CodecRegistry {
+	r := &CodecRegistry{
+		byContentType: make(map[string]Codec),
+		byName:        make(map[string]Codec),
+		fallback:      fallback,
+	}
+	r.Register(fallback)
+	for _, c := range codecs {
+		r.Register(c)
+	}
+	return r
+}
+
+// DefaultCodecs — все поддерживаемые форматы, JSON v1 для записей без заголовка.
+func DefaultCodecs() *CodecRegistry {
+	return NewCodecRegistry(JSONCodec{}, ProtobufCodec{})
+}
+
+func (r *CodecRegistry) Register(c Codec) {
+	r.byContentType[c.ContentType()] = c
+	r.byName[c.Name()] = c
+}
+
+// SetSchemaRegistry включает декодирование сообщений в формате Confluent
+// (магический байт и идентификатор схемы) от сторонних продюсеров.
+func (r *CodecRegistry) SetSchemaRegistry(d *schemaregistry.Deserializer) {
+	r.confluent = d
+}
+
+func (r *CodecRegistry) ByName(name string) (Codec, error) {
+	c, ok := r.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown codec %q", name)
+	}
+	return c, nil
+}
+
+func (r *CodecRegistry) Decode(ctx context.Context, rec *kgo.Record) (model.ValueRecord, error) {
+	ct := headerValue(rec, ContentTypeHeader)
+	if ct == "" {
+		if schemaregistry.IsFramed(rec.Value) {
+			if r.confluent == nil {
+				return model.ValueRecord{}, errors.New("confluent-framed record but schema registry is not configured")
+			}
+			return r.confluent.Decode(ctx, rec.Value)
+		}
+		return r.fallback.Decode(rec.Value)
+	}
+
+	c, ok := r.byContentType[strings.TrimSpace(ct)]
+	if !ok {
+		return model.ValueRecord{}, fmt.Errorf("unsupported content type %q", ct)
+	}
+	return c.Decode(rec.Value)
+}
+
+func headerValue(rec *kgo.Record, key string) string {
+	for _, h := range rec.Headers {
+		if strings.EqualFold(h.Key, key) {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) Name() string        { return "json" }
+func (JSONCodec) ContentType() string { return ContentTypeJSONv1 }
+
+func (JSONCodec) Encode(rec model.ValueRecord) ([]byte, error) {
+	return json.Marshal(rec)
+}
+
+func (JSONCodec) Decode(data []byte) (model.ValueRecord, error) {
+	var rec model.ValueRecord
+	err := json.Unmarshal(data, &rec)
+	return rec, err
+}
+
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) Name() string        { return "protobuf" }
+func (ProtobufCodec) ContentType() string { return ContentTypeProtobufv1 }
+
+func (ProtobufCodec) Encode(rec model.ValueRecord) ([]byte, error) {
+	msg := &pb.ValueRecord{
+		Uuid:  rec.UUID,
+		Value: rec.Value,
+	}
+	if !rec.Timestamp.IsZero() {
+		msg.Timestamp = timestamppb.New(rec.Timestamp)
+	}
+	return proto.Marshal(msg)
+}
+
+func (ProtobufCodec) Decode(data []byte) (model.ValueRecord, error) {
+	var msg pb.ValueRecord
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return model.ValueRecord{}, err
+	}
+
+	rec := model.ValueRecord{
+		UUID:  msg.Uuid,
+		Value: msg.Value,
+	}
+	if msg.Timestamp != nil {
+		rec.Timestamp = msg.Timestamp.AsTime()
+	}
+	return rec, nil
+}
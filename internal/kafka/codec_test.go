@@ -0,0 +1,171 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/schemaregistry"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/service/mocks"
+)
+
+func TestCodecRegistry_Decode(t *testing.T) {
+	registry := DefaultCodecs()
+	rec := model.ValueRecord{
+		UUID:      "a1b2c3d4-e5f6-47b8-89d0-e1f2a3b4c5d6",
+		Timestamp: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Value:     []int64{3, 1, 2},
+	}
+
+	for _, name := range []string{"json", "protobuf"} {
+		t.Run(name, func(t *testing.T) {
+			codec, err := registry.ByName(name)
+			require.NoError(t, err)
+
+			data, err := codec.Encode(rec)
+			require.NoError(t, err)
+
+			decoded, err := registry.Decode(context.Background(), &kgo.Record{
+				Value:   data,
+				Headers: []kgo.RecordHeader{{Key: ContentTypeHeader, Value: []byte(codec.ContentType())}},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, rec.UUID, decoded.UUID)
+			assert.True(t, rec.Timestamp.Equal(decoded.Timestamp))
+			assert.Equal(t, rec.Value, decoded.Value)
+		})
+	}
+
+	t.Run("Legacy JSON without header", func(t *testing.T) {
+		decoded, err := registry.Decode(context.Background(), &kgo.Record{
+			Value: []byte(`{"uuid":"abc","timestamp":"2025-01-01T12:00:00Z","value":[1]}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "abc", decoded.UUID)
+	})
+
+	t.Run("Unknown content type", func(t *testing.T) {
+		_, err := registry.Decode(context.Background(), &kgo.Record{
+			Value:   []byte(`{}`),
+			Headers: []kgo.RecordHeader{{Key: ContentTypeHeader, Value: []byte("text/plain")}},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestProcessor_DecodeErrors(t *testing.T) {
+	var status atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	codecs := DefaultCodecs()
+	codecs.SetSchemaRegistry(schemaregistry.NewDeserializer(
+		schemaregistry.NewHTTPResolver(srv.URL, "", "", time.Second),
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	))
+	bindings, err := NewBindings([]config.TopicBinding{{Topic: testTopic}}, config.ValidationConfig{}, codecs)
+	require.NoError(t, err)
+	p := &processor{bindings: bindings}
+
+	framed := &kgo.Record{Topic: testTopic, Value: []byte{0, 0, 0, 0, 7, 1}}
+	process := func() *processError {
+		_, _, err := p.process(context.Background(), framed)
+		var perr *processError
+		require.ErrorAs(t, err, &perr)
+		return perr
+	}
+
+	t.Run("Registry unavailable is retried", func(t *testing.T) {
+		status.Store(http.StatusServiceUnavailable)
+		perr := process()
+		assert.True(t, perr.retry)
+		assert.ErrorIs(t, perr, schemaregistry.ErrUnavailable)
+	})
+
+	t.Run("Unknown schema is rejected", func(t *testing.T) {
+		status.Store(http.StatusNotFound)
+		perr := process()
+		assert.False(t, perr.retry)
+		assert.Equal(t, reasonDecodeError, perr.reason)
+	})
+
+	t.Run("Malformed payload is rejected", func(t *testing.T) {
+		_, _, err := p.process(context.Background(), &kgo.Record{Topic: testTopic, Value: []byte("{")})
+		var perr *processError
+		require.ErrorAs(t, err, &perr)
+		assert.False(t, perr.retry)
+		assert.Equal(t, stageDecode, perr.stage)
+	})
+}
+
+func TestProcessor_PipelineLatency(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics.PipelineLatency)
+	samples := func() uint64 {
+		mfs, err := reg.Gather()
+		require.NoError(t, err)
+		require.Len(t, mfs, 1)
+		return mfs[0].GetMetric()[0].GetHistogram().GetSampleCount()
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var discard atomic.Bool
+	svc := service.New(logger, &mocks.MockMaxValueRepository{
+		SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+			if discard.Load() {
+				return nil, nil
+			}
+			return &model.MaxValueResult{UUID: rec.UUID}, nil
+		},
+	})
+	p := &processor{service: svc, bindings: newTestBindings(t)}
+	record := func() *kgo.Record {
+		value, err := JSONCodec{}.Encode(testValueRecord(time.Now()))
+		require.NoError(t, err)
+		return &kgo.Record{Topic: testTopic, Value: value}
+	}
+
+	t.Run("Saved record is observed", func(t *testing.T) {
+		before := samples()
+		_, res, err := p.process(context.Background(), record())
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		assert.Equal(t, before+1, samples())
+	})
+
+	t.Run("Discarded record is not observed", func(t *testing.T) {
+		discard.Store(true)
+		defer discard.Store(false)
+
+		before := samples()
+		_, res, err := p.process(context.Background(), record())
+		require.NoError(t, err)
+		assert.Nil(t, res)
+		assert.Equal(t, before, samples())
+	})
+
+	t.Run("Replayed record is not observed", func(t *testing.T) {
+		replay := &processor{service: svc, bindings: p.bindings, replay: true}
+
+		before := samples()
+		_, _, err := replay.process(context.Background(), record())
+		require.NoError(t, err)
+		assert.Equal(t, before, samples())
+	})
+}
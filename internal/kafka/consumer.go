@@ -2,71 +2,191 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strconv"
 
-	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
 	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrConsumerFatal оборачивает ошибку, после которой Run прекращает чтение.
+var ErrConsumerFatal = errors.New("kafka consumer: fatal error")
+
+// FranzConsumer читает топик одним клиентом и раздаёт записи воркерам партиций.
+// Офсет записи помечается к коммиту только после её сохранения или отклонения. Группа использует
+// cooperative-sticky балансировку, а ребаланс, отбирающий партиции, ждёт,
+// пока их уже полученные записи будут обработаны и закоммичены.
 type FranzConsumer struct {
-	client  *kgo.Client
-	log     *slog.Logger
-	service *service.Service
+	client   *kgo.Client
+	group    string
+	tracer   trace.Tracer
+	log      *slog.Logger
+	pipeline *pipeline
+	pool     *workerPool
 }
 
-func NewConsumer(brokers []string, group, topic string, svc *service.Service, log *slog.Logger) (Consumer, error) {
-	client, err := kgo.NewClient(
-		kgo.SeedBrokers(brokers...),
-		kgo.ConsumerGroup(group),
-		kgo.ConsumeTopics(topic),
+func NewConsumer(
+	common []kgo.Opt,
+	cfg config.KafkaConfig,
+	workers int,
+	svc *service.Service,
+	bindings *Bindings,
+	tp trace.TracerProvider,
+	log *slog.Logger,
+) (Consumer, error) {
+	c := &FranzConsumer{group: cfg.Group, tracer: newTracer(tp), log: log.With("component", "kafka_consumer")}
+	c.pipeline = &pipeline{
+		processor: &processor{
+			service:  svc,
+			bindings: bindings,
+		},
+		rejectedTopic: cfg.RejectedTopic,
+		// Отклонённая запись отправляется синхронно: её офсет помечается
+		// следом, и Close не должен потерять ещё не отправленную копию.
+		produce: func(ctx context.Context, r *kgo.Record, promise func(*kgo.Record, error)) {
+			promise(c.client.ProduceSync(ctx, r).First())
+		},
+		log: c.log,
+	}
+	c.pool = newWorkerPool(workers, cfg.MaxInFlight, cfg.MaxAttempts, c.process, c.exhausted)
+
+	opts := append(bindings.consumeOpts(),
+		kgo.ConsumerGroup(cfg.Group),
+		kgo.Balancers(kgo.CooperativeStickyBalancer()),
+		kgo.AutoCommitMarks(),
+		kgo.BlockRebalanceOnPoll(),
+		kgo.OnPartitionsAssigned(c.onAssigned),
+		kgo.OnPartitionsRevoked(c.onRevoked),
+		kgo.OnPartitionsLost(c.onLost),
 	)
+	client, err := newClient(common, "consumer", opts...)
 	if err != nil {
 		return nil, err
 	}
+	c.client = client
 
-	return &FranzConsumer{
-		client:  client,
-		log:     log.With("component", "kafka_consumer"),
-		service: svc,
-	}, nil
+	return c, nil
 }
 
+// Run читает топик, пока не отменён ctx или не закрыт клиент, и в этих случаях
+// возвращает nil. Ошибка, после которой чтение невозможно без вмешательства
+// (отказ в доступе, удалённый топик), возвращается обёрнутой в ErrConsumerFatal;
+// остальные ошибки опроса только логируются.
 func (c *FranzConsumer) Run(ctx context.Context) error {
 	for {
 		fetches := c.client.PollFetches(ctx)
-
-		if errs := fetches.Errors(); len(errs) > 0 {
-			for _, e := range errs {
-				c.log.Error("poll error", slog.Any("error", e))
-			}
-			continue
+		if fetches.IsClientClosed() || ctx.Err() != nil {
+			c.client.AllowRebalance()
+			return nil
 		}
 
-		fetches.EachRecord(func(r *kgo.Record) {
-			var msg model.ValueRecord
+		if err := checkPollErrors(c.log, fetches); err != nil {
+			c.client.AllowRebalance()
+			return err
+		}
 
-			if err := json.Unmarshal(r.Value, &msg); err != nil {
-				c.log.Error("decode error", slog.Any("error", err))
+		fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+			if len(p.Records) == 0 {
 				return
 			}
+			observeLag(p)
 
-			maxVal := c.service.ComputeMax(msg.Value)
+			c.pool.dispatch(ctx, topicPartition{topic: p.Topic, partition: p.Partition}, p.Records)
+		})
 
-			err := c.service.SaveMaxValue(ctx, model.MaxValueRecord{
-				UUID:      msg.UUID,
-				Timestamp: msg.Timestamp,
-				MaxValue:  maxVal,
-			})
+		// Записи переданы воркерам; ребаланс может отозвать партиции,
+		// onRevoked дождётся их обработки.
+		c.client.AllowRebalance()
+	}
+}
 
-			if err != nil {
-				c.log.Error("failed to save max record", slog.Any("error", err))
+// checkPollErrors логирует ошибки опроса и возвращает первую фатальную,
+// обёрнутую в ErrConsumerFatal.
+func checkPollErrors(log *slog.Logger, fetches kgo.Fetches) error {
+	var fatal error
+	fetches.EachError(func(topic string, partition int32, err error) {
+		if isFatal(err) {
+			if fatal == nil {
+				fatal = fmt.Errorf("%w: %s[%d]: %w", ErrConsumerFatal, topic, partition, err)
 			}
-		})
+			return
+		}
+		log.Error("poll error",
+			slog.String("topic", topic),
+			slog.Int("partition", int(partition)),
+			slog.Any("error", err),
+		)
+	})
+	return fatal
+}
+
+func observeLag(p kgo.FetchTopicPartition) {
+	last := p.Records[len(p.Records)-1]
+	metrics.KafkaConsumerLag.
+		WithLabelValues(p.Topic, strconv.Itoa(int(p.Partition))).
+		Set(float64(p.HighWatermark - last.Offset - 1))
+}
+
+// isFatal сообщает, что ошибка опроса не исчезнет без вмешательства:
+// клиенту отказано в доступе или читаемый топик удалён.
+func isFatal(err error) bool {
+	for _, fatal := range []error{
+		kerr.SaslAuthenticationFailed,
+		kerr.TopicAuthorizationFailed,
+		kerr.GroupAuthorizationFailed,
+		kerr.ClusterAuthorizationFailed,
+		kerr.UnknownTopicOrPartition,
+		kerr.UnknownTopicID,
+	} {
+		if errors.Is(err, fatal) {
+			return true
+		}
 	}
+	return false
+}
+
+// process возвращает ошибку, если запись не удалось сохранить: её офсет не помечается,
+// и воркер повторит запись. Отклонённая запись помечается — повторная попытка
+// не исправит ни ошибку декодирования, ни валидации. Прерванная (партиция потеряна
+// или консьюмер остановлен) не помечается и будет прочитана заново.
+func (c *FranzConsumer) process(ctx context.Context, r *kgo.Record) error {
+	ctx, span := startProcessSpan(ctx, c.tracer, r, c.group)
+
+	_, err := c.pipeline.handle(ctx, r)
+	endSpan(span, err)
+	if err != nil {
+		c.log.ErrorContext(ctx, "failed to process record, will retry", slog.Any("error", err))
+		return err
+	}
+
+	if ctx.Err() == nil {
+		c.client.MarkCommitRecords(r)
+	}
+	return nil
+}
+
+// exhausted отклоняет запись, которую не удалось сохранить за cfg.MaxAttempts
+// попыток, и помечает её офсет, чтобы партиция не стояла на ней.
+func (c *FranzConsumer) exhausted(ctx context.Context, r *kgo.Record, err error) {
+	c.log.ErrorContext(ctx, "retries exhausted, rejecting record",
+		slog.String("topic", r.Topic),
+		slog.Int("partition", int(r.Partition)),
+		slog.Int64("offset", r.Offset),
+		slog.Any("error", err),
+	)
+	c.pipeline.reject(ctx, r, reasonRetriesExhausted)
+	c.client.MarkCommitRecords(r)
 }
 
+// Close покидает группу: onRevoked дожидается обработки полученных записей
+// и коммитит офсеты до выхода.
 func (c *FranzConsumer) Close() {
 	c.client.Close()
+	c.pool.stop()
 }
@@ -0,0 +1,239 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/service/mocks"
+)
+
+func TestFranzConsumer_CloseCommitsProcessed(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	common := newTestCluster(t, 2)
+	now := time.Now().UTC()
+
+	const perPartition = 10
+	for p := int32(0); p < 2; p++ {
+		recs := make([]model.ValueRecord, perPartition)
+		for i := range recs {
+			recs[i] = testValueRecord(now)
+		}
+		produceTestRecords(t, common, p, recs...)
+	}
+
+	var saved atomic.Int64
+	svc := service.New(logger, &mocks.MockMaxValueRepository{
+		SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+			time.Sleep(10 * time.Millisecond)
+			saved.Add(1)
+			return &model.MaxValueResult{UUID: rec.UUID}, nil
+		},
+	})
+	bindings := newTestBindings(t)
+
+	cfg := config.KafkaConfig{Topic: testTopic, Group: "test-group", MaxInFlight: 4}
+	newConsumer := func() Consumer {
+		c, err := NewConsumer(common, cfg, 2, svc, bindings, noop.NewTracerProvider(), logger)
+		require.NoError(t, err)
+		go func() { _ = c.Run(ctx) }()
+		return c
+	}
+
+	// Первый консьюмер останавливается посреди обработки.
+	first := newConsumer()
+	require.Eventually(t, func() bool { return saved.Load() >= 3 }, 10*time.Second, 5*time.Millisecond)
+	first.Close()
+
+	processed := saved.Load()
+	assert.Equal(t, processed, committedTotal(t, common, cfg.Group))
+
+	// Второй продолжает с закоммиченных офсетов: без потерь и повторов.
+	second := newConsumer()
+	defer second.Close()
+
+	require.Eventually(t, func() bool { return saved.Load() == 2*perPartition }, 10*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(2*perPartition), saved.Load())
+}
+
+func TestFranzConsumer_PersistFailureNotCommitted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	common := newTestCluster(t, 1)
+	now := time.Now().UTC()
+	produceTestRecords(t, common, 0, testValueRecord(now), testValueRecord(now))
+
+	var attempts, saved atomic.Int64
+	var healthy atomic.Bool
+	svc := service.New(logger, &mocks.MockMaxValueRepository{
+		SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+			attempts.Add(1)
+			if !healthy.Load() {
+				return nil, errors.New("connection refused")
+			}
+			saved.Add(1)
+			return &model.MaxValueResult{UUID: rec.UUID}, nil
+		},
+	})
+	bindings := newTestBindings(t)
+	cfg := config.KafkaConfig{Topic: testTopic, Group: "test-group", MaxInFlight: 4}
+
+	newConsumer := func() Consumer {
+		c, err := NewConsumer(common, cfg, 1, svc, bindings, noop.NewTracerProvider(), logger)
+		require.NoError(t, err)
+		go func() { _ = c.Run(ctx) }()
+		return c
+	}
+
+	// База недоступна: первая запись повторяется, вторая не обрабатывается,
+	// и при закрытии ничего не коммитится.
+	first := newConsumer()
+	require.Eventually(t, func() bool { return attempts.Load() >= 3 }, 10*time.Second, 5*time.Millisecond)
+	first.Close()
+	assert.Equal(t, int64(0), committedTotal(t, common, cfg.Group))
+
+	// После восстановления новый консьюмер читает обе записи заново.
+	healthy.Store(true)
+	second := newConsumer()
+	require.Eventually(t, func() bool { return saved.Load() == 2 }, 10*time.Second, 5*time.Millisecond)
+	second.Close()
+	assert.Equal(t, int64(2), committedTotal(t, common, cfg.Group))
+}
+
+func TestFranzConsumer_PoisonRecordRejected(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	common := newTestCluster(t, 1)
+	now := time.Now().UTC()
+	invalid, unavailable, valid := testValueRecord(now), testValueRecord(now), testValueRecord(now)
+	produceTestRecords(t, common, 0, invalid, unavailable, valid)
+
+	var saved atomic.Int64
+	svc := service.New(logger, &mocks.MockMaxValueRepository{
+		SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+			switch rec.UUID {
+			case invalid.UUID:
+				return nil, fmt.Errorf("%w: value out of range", repository.ErrInvalidRecord)
+			case unavailable.UUID:
+				return nil, errors.New("connection refused")
+			}
+			saved.Add(1)
+			return &model.MaxValueResult{UUID: rec.UUID}, nil
+		},
+	})
+	bindings := newTestBindings(t)
+	cfg := config.KafkaConfig{Topic: testTopic, Group: "test-group", MaxInFlight: 4, MaxAttempts: 3, RejectedTopic: testRejectedTopic}
+
+	c, err := NewConsumer(common, cfg, 1, svc, bindings, noop.NewTracerProvider(), logger)
+	require.NoError(t, err)
+	go func() { _ = c.Run(ctx) }()
+
+	// Отвергнутая хранилищем запись отклоняется сразу, недоступность базы —
+	// после MaxAttempts попыток; партиция не стоит ни на одной из них.
+	require.Eventually(t, func() bool { return saved.Load() == 1 }, 10*time.Second, 5*time.Millisecond)
+	c.Close()
+	assert.Equal(t, int64(3), committedTotal(t, common, cfg.Group))
+
+	rejected := readCommitted(t, common, testRejectedTopic, 2)
+	require.Len(t, rejected, 2)
+	reasons := make([]string, 0, len(rejected))
+	for _, r := range rejected {
+		for _, h := range r.Headers {
+			if h.Key == rejectReasonHeader {
+				reasons = append(reasons, string(h.Value))
+			}
+		}
+	}
+	assert.Equal(t, []string{reasonInvalidRecord, reasonRetriesExhausted}, reasons)
+}
+
+func TestFranzConsumer_RunExits(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	common := newTestCluster(t, 1)
+	cfg := config.KafkaConfig{Topic: testTopic, Group: "test-group", MaxInFlight: 1}
+
+	run := func(ctx context.Context, c Consumer) <-chan error {
+		done := make(chan error, 1)
+		go func() { done <- c.Run(ctx) }()
+		return done
+	}
+
+	t.Run("Context canceled", func(t *testing.T) {
+		c, err := NewConsumer(common, cfg, 1, nil, newTestBindings(t), noop.NewTracerProvider(), logger)
+		require.NoError(t, err)
+		defer c.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := run(ctx, c)
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after context cancellation")
+		}
+	})
+
+	t.Run("Client closed", func(t *testing.T) {
+		c, err := NewConsumer(common, cfg, 1, nil, newTestBindings(t), noop.NewTracerProvider(), logger)
+		require.NoError(t, err)
+
+		done := run(context.Background(), c)
+		c.Close()
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after Close")
+		}
+	})
+}
+
+func TestIsFatal(t *testing.T) {
+	assert.True(t, isFatal(kerr.TopicAuthorizationFailed))
+	assert.True(t, isFatal(&kgo.ErrGroupSession{Err: kerr.GroupAuthorizationFailed}))
+	assert.True(t, isFatal(kerr.UnknownTopicOrPartition))
+	assert.False(t, isFatal(kerr.NotLeaderForPartition))
+	assert.False(t, isFatal(&kgo.ErrDataLoss{}))
+}
+
+func committedTotal(t *testing.T, common []kgo.Opt, group string) int64 {
+	client, err := kgo.NewClient(common...)
+	require.NoError(t, err)
+	defer client.Close()
+
+	offsets, err := kadm.NewClient(client).FetchOffsets(context.Background(), group)
+	require.NoError(t, err)
+
+	var total int64
+	offsets.Each(func(o kadm.OffsetResponse) {
+		total += o.At
+	})
+	return total
+}
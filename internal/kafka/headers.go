@@ -0,0 +1,61 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/tracing"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Заголовки происхождения записи; content-type описан в codec.go.
+const (
+	TraceParentHeader = "traceparent"
+	ProducerIDHeader  = "producer-id"
+	IngestedAtHeader  = "ingested-at"
+)
+
+// originHeaders описывает новую запись. В traceparent записывается текущий спан
+// из ctx, а без него продолжается трассировка из метаданных или начинается новая.
+// Удалённый спан в ctx означает, что свой спан не записывается (трассировка
+// отключена), и запись получает дочерний к нему контекст.
+func originHeaders(ctx context.Context, producerID, contentType string) []kgo.RecordHeader {
+	tp := tracing.NewTraceParent()
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		tp = tracing.FromSpanContext(sc)
+		if sc.IsRemote() {
+			tp = tp.Child()
+		}
+	} else if md, ok := tracing.FromContext(ctx); ok && md.TraceParent.IsValid() {
+		tp = md.TraceParent.Child()
+	}
+
+	headers := []kgo.RecordHeader{
+		{Key: ContentTypeHeader, Value: []byte(contentType)},
+		{Key: TraceParentHeader, Value: []byte(tp.String())},
+		{Key: IngestedAtHeader, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+	}
+	if producerID != "" {
+		headers = append(headers, kgo.RecordHeader{Key: ProducerIDHeader, Value: []byte(producerID)})
+	}
+	return headers
+}
+
+// recordContext добавляет в ctx происхождение записи из её заголовков, а спан
+// продюсера из traceparent становится родителем спанов обработки.
+// Некорректные значения пропускаются: запись обрабатывается и без них.
+func recordContext(ctx context.Context, r *kgo.Record) context.Context {
+	md := tracing.Metadata{
+		ProducerID:  headerValue(r, ProducerIDHeader),
+		ContentType: headerValue(r, ContentTypeHeader),
+	}
+	if tp, err := tracing.ParseTraceParent(headerValue(r, TraceParentHeader)); err == nil {
+		md.TraceParent = tp
+		ctx = trace.ContextWithRemoteSpanContext(ctx, tp.SpanContext())
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, headerValue(r, IngestedAtHeader)); err == nil {
+		md.IngestedAt = ts
+	}
+	return tracing.WithMetadata(ctx, md)
+}
@@ -0,0 +1,86 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const rejectReasonHeader = "reject-reason"
+
+// pipeline — обработка записи консьюмером поверх processor: метрики конвейера
+// и перенаправление отклонённых записей. produce позволяет транзакционному
+// консьюмеру отправлять отклонённые записи в рамках своей транзакции.
+type pipeline struct {
+	processor     *processor
+	rejectedTopic string
+	produce       func(ctx context.Context, r *kgo.Record, promise func(*kgo.Record, error))
+	log           *slog.Logger
+}
+
+// handle возвращает ошибку только при временном сбое: отклонённые записи
+// учитываются и перенаправляются, повторная попытка их не исправит.
+func (p *pipeline) handle(ctx context.Context, r *kgo.Record) (*model.MaxValueResult, error) {
+	partition := strconv.Itoa(int(r.Partition))
+	metrics.KafkaRecordsConsumed.WithLabelValues(r.Topic, partition).Inc()
+
+	msg, res, err := p.processor.process(ctx, r)
+	if err != nil {
+		var perr *processError
+		errors.As(err, &perr)
+
+		if perr.stage != stageDecode {
+			metrics.KafkaRecordsDecoded.WithLabelValues(r.Topic, partition).Inc()
+		}
+		metrics.KafkaRecordsFailed.WithLabelValues(r.Topic, partition, perr.stage).Inc()
+
+		if perr.retry {
+			return nil, err
+		}
+
+		switch perr.stage {
+		case stageDecode:
+			p.log.ErrorContext(ctx, "decode error", slog.Any("error", err))
+		case stageValidate:
+			p.log.WarnContext(ctx, "record rejected", slog.String("uuid", msg.UUID), slog.Any("error", err))
+		default:
+			p.log.ErrorContext(ctx, "record rejected by storage", slog.String("uuid", msg.UUID), slog.Any("error", err))
+		}
+		p.reject(ctx, r, perr.reason)
+		return nil, nil
+	}
+
+	metrics.KafkaRecordsDecoded.WithLabelValues(r.Topic, partition).Inc()
+
+	return res, nil
+}
+
+// reject учитывает отклонённую запись и, если задан rejectedTopic,
+// пересылает её туда без изменений с причиной в заголовке.
+func (p *pipeline) reject(ctx context.Context, r *kgo.Record, reason string) {
+	metrics.RecordsRejected.WithLabelValues(reason).Inc()
+
+	if p.rejectedTopic == "" {
+		return
+	}
+
+	rejected := &kgo.Record{
+		Topic:   p.rejectedTopic,
+		Key:     r.Key,
+		Value:   r.Value,
+		Headers: append(slices.Clone(r.Headers), kgo.RecordHeader{Key: rejectReasonHeader, Value: []byte(reason)}),
+	}
+
+	p.produce(ctx, rejected, func(_ *kgo.Record, err error) {
+		if err != nil {
+			metrics.KafkaProduceErrors.WithLabelValues(p.rejectedTopic).Inc()
+			p.log.ErrorContext(ctx, "failed to route rejected record", slog.Any("error", err))
+		}
+	})
+}
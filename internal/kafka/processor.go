@@ -0,0 +1,134 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+	"github.com/Pavel26ru/aggregator-service/internal/schemaregistry"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/validation"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const (
+	reasonDecodeError        = "decode_error"
+	reasonIncompatibleSchema = "incompatible_schema"
+	reasonInvalidRecord      = "invalid_record"
+	reasonRetriesExhausted   = "retries_exhausted"
+)
+
+// Этапы обработки для метрики kafka_records_failed_total.
+const (
+	stageDecode   = "decode"
+	stageValidate = "validate"
+	stagePersist  = "persist"
+)
+
+// processError описывает, на каком этапе и почему запись не была сохранена.
+// retry означает временный сбой: запись повторяют, а не отклоняют.
+type processError struct {
+	stage  string
+	reason string
+	retry  bool
+	err    error
+}
+
+func (e *processError) Error() string { return e.stage + ": " + e.err.Error() }
+func (e *processError) Unwrap() error { return e.err }
+
+// processor — общая для консьюмера и повторной обработки логика:
+// декодирование, валидация, вычисление агрегатов и сохранение
+// по привязке топика записи.
+type processor struct {
+	service  *service.Service
+	bindings *Bindings
+	// replay сохраняет записи без источника: хранилище не узнаёт в них уже
+	// применённые и пересчитывает значения.
+	replay bool
+}
+
+// process возвращает декодированную запись и состояние максимума после сохранения;
+// результат nil без ошибки означает, что запись отброшена политикой конфликтов
+// или привязка топика не вычисляет max.
+func (p *processor) process(ctx context.Context, r *kgo.Record) (model.ValueRecord, *model.MaxValueResult, error) {
+	bnd := p.bindings.lookup(r.Topic)
+	if bnd == nil {
+		err := fmt.Errorf("no binding for topic %q", r.Topic)
+		return model.ValueRecord{}, nil, &processError{stage: stageDecode, reason: reasonUnboundTopic, err: err}
+	}
+
+	msg, err := bnd.decode(ctx, r)
+	if err != nil {
+		// Недоступный реестр схем не делает запись некорректной: её повторяют.
+		if errors.Is(err, schemaregistry.ErrUnavailable) {
+			return msg, nil, &processError{stage: stageDecode, retry: true, err: err}
+		}
+		reason := reasonDecodeError
+		if errors.Is(err, schemaregistry.ErrIncompatible) {
+			reason = reasonIncompatibleSchema
+		}
+		return msg, nil, &processError{stage: stageDecode, reason: reason, err: err}
+	}
+
+	if err := bnd.validator.Validate(msg); err != nil {
+		reason := "invalid"
+		var verr *validation.Error
+		if errors.As(err, &verr) {
+			reason = string(verr.Reason)
+		}
+		return msg, nil, &processError{stage: stageValidate, reason: reason, err: err}
+	}
+
+	var res *model.MaxValueResult
+	if bnd.saveMax {
+		rec := model.MaxValueRecord{
+			UUID:      msg.UUID,
+			Timestamp: msg.Timestamp,
+			MaxValue:  p.service.ComputeMax(msg.Value),
+		}
+		if !p.replay {
+			rec.Source = &model.RecordSource{Topic: r.Topic, Partition: r.Partition, Offset: r.Offset}
+		}
+		res, err = p.service.SaveMaxValue(ctx, rec)
+		if err != nil {
+			return msg, nil, persistError(err)
+		}
+	}
+
+	if len(bnd.aggregates) > 0 {
+		values := make(map[string]int64, len(bnd.aggregates))
+		for _, name := range bnd.aggregates {
+			values[name] = p.service.Compute(name, msg.Value)
+		}
+		err := p.service.SaveAggregates(ctx, model.AggregateRecord{
+			UUID:      msg.UUID,
+			Tag:       bnd.tag,
+			Timestamp: msg.Timestamp,
+			Values:    values,
+		})
+		if err != nil {
+			return msg, nil, persistError(err)
+		}
+	}
+
+	// Задержку учитывают, только если что-то сохранено: max, отброшенный
+	// политикой конфликтов, не считается, как и старые записи replay.
+	if !p.replay && (res != nil || len(bnd.aggregates) > 0) {
+		metrics.PipelineLatency.Observe(time.Since(msg.Timestamp).Seconds())
+	}
+
+	return msg, res, nil
+}
+
+// persistError отличает отвергнутую хранилищем запись от временного сбоя сохранения.
+func persistError(err error) *processError {
+	if errors.Is(err, repository.ErrInvalidRecord) {
+		return &processError{stage: stagePersist, reason: reasonInvalidRecord, err: err}
+	}
+	return &processError{stage: stagePersist, retry: true, err: err}
+}
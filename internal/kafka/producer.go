@@ -2,50 +2,69 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
 
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
 	"github.com/Pavel26ru/aggregator-service/internal/model"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type producer struct {
-	client *kgo.Client
-	topic  string
-	log    *slog.Logger
+	client     *kgo.Client
+	topic      string
+	producerID string
+	codec      Codec
+	tracer     trace.Tracer
+	log        *slog.Logger
 }
 
-func NewProducer(brokers []string, topic string, log *slog.Logger) (Producer, error) {
-	client, err := kgo.NewClient(
-		kgo.SeedBrokers(brokers...),
-	)
+// NewProducer создаёт продюсера, который помечает записи заголовками
+// происхождения: traceparent, producer-id, content-type и ingested-at.
+func NewProducer(
+	common []kgo.Opt,
+	topic, producerID string,
+	codec Codec,
+	tp trace.TracerProvider,
+	log *slog.Logger,
+) (Producer, error) {
+	client, err := newClient(common, "producer")
 	if err != nil {
 		return nil, err
 	}
 
 	return &producer{
-		client: client,
-		topic:  topic,
-		log:    log.With("component", "kafka_producer"),
+		client:     client,
+		topic:      topic,
+		producerID: producerID,
+		codec:      codec,
+		tracer:     newTracer(tp),
+		log:        log.With("component", "kafka_producer"),
 	}, nil
 }
 
 func (p *producer) Produce(ctx context.Context, rec model.ValueRecord) error {
-	value, err := json.Marshal(rec)
+	value, err := p.codec.Encode(rec)
 	if err != nil {
 		return err
 	}
 
+	// Спан завершается при подтверждении доставки.
+	ctx, span := startSendSpan(ctx, p.tracer, p.topic)
+
 	record := &kgo.Record{
-		Topic: p.topic,
-		Value: value,
-		Key:   []byte(rec.UUID),
+		Topic:   p.topic,
+		Value:   value,
+		Key:     []byte(rec.UUID),
+		Headers: originHeaders(ctx, p.producerID, p.codec.ContentType()),
 	}
 
 	p.client.Produce(ctx, record, func(_ *kgo.Record, err error) {
 		if err != nil {
-			p.log.Error("failed to deliver record", slog.Any("error", err))
+			metrics.KafkaProduceErrors.WithLabelValues(p.topic).Inc()
+			p.log.ErrorContext(ctx, "failed to deliver record", slog.Any("error", err))
 		}
+		endSpan(span, err)
 	})
 
 	return nil
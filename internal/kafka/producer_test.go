@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/Pavel26ru/aggregator-service/internal/tracing"
+)
+
+func TestProducer_OriginHeaders(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	common := newTestCluster(t, 1)
+
+	producer, err := NewProducer(common, testTopic, "ingest-1", JSONCodec{}, noop.NewTracerProvider(), logger)
+	require.NoError(t, err)
+	defer producer.Close()
+
+	parent := tracing.NewTraceParent()
+	before := time.Now().UTC()
+	require.NoError(t, producer.Produce(tracing.WithMetadata(ctx, tracing.Metadata{TraceParent: parent}), testValueRecord(before)))
+	require.NoError(t, producer.Produce(ctx, testValueRecord(before)))
+
+	client, err := kgo.NewClient(append(common, kgo.ConsumeTopics(testTopic), kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()))...)
+	require.NoError(t, err)
+	defer client.Close()
+
+	var records []*kgo.Record
+	for len(records) < 2 && ctx.Err() == nil {
+		records = append(records, client.PollFetches(ctx).Records()...)
+	}
+	require.Len(t, records, 2)
+
+	continued, ok := tracing.FromContext(recordContext(ctx, records[0]))
+	require.True(t, ok)
+	assert.Equal(t, parent.TraceID, continued.TraceParent.TraceID)
+	assert.NotEqual(t, parent.SpanID, continued.TraceParent.SpanID)
+	assert.Equal(t, "ingest-1", continued.ProducerID)
+	assert.Equal(t, ContentTypeJSONv1, continued.ContentType)
+	assert.False(t, continued.IngestedAt.Before(before))
+
+	started, ok := tracing.FromContext(recordContext(ctx, records[1]))
+	require.True(t, ok)
+	assert.True(t, started.TraceParent.IsValid())
+	assert.NotEqual(t, parent.TraceID, started.TraceParent.TraceID)
+}
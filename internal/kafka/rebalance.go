@@ -0,0 +1,72 @@
+package kafka
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Типы изменений назначения для метрики kafka_partition_assignment_changes_total.
+const (
+	assignmentAssigned = "assigned"
+	assignmentRevoked  = "revoked"
+	assignmentLost     = "lost"
+)
+
+// onAssigned вызывается до начала чтения назначенных партиций.
+func (c *FranzConsumer) onAssigned(_ context.Context, _ *kgo.Client, assigned map[string][]int32) {
+	recordAssignment(c.log, assignmentAssigned, assigned)
+}
+
+// onRevoked дожидается обработки уже полученных записей отзываемых партиций
+// и синхронно коммитит их офсеты, пока партиции ещё принадлежат этому участнику.
+func (c *FranzConsumer) onRevoked(ctx context.Context, cl *kgo.Client, revoked map[string][]int32) {
+	c.pool.drain(toTopicPartitions(revoked))
+
+	if err := cl.CommitMarkedOffsets(ctx); err != nil {
+		c.log.Error("failed to commit offsets on revoke", slog.Any("error", err))
+	}
+
+	recordAssignment(c.log, assignmentRevoked, revoked)
+}
+
+// onLost прерывает обработку потерянных партиций без коммита:
+// они уже могут принадлежать другому участнику группы.
+func (c *FranzConsumer) onLost(_ context.Context, _ *kgo.Client, lost map[string][]int32) {
+	c.pool.abort(toTopicPartitions(lost))
+	recordAssignment(c.log, assignmentLost, lost)
+}
+
+func recordAssignment(log *slog.Logger, event string, partitions map[string][]int32) {
+	for topic, ps := range partitions {
+		if len(ps) == 0 {
+			continue
+		}
+
+		log.Info("partitions "+event, slog.String("topic", topic), slog.Any("partitions", ps))
+		metrics.KafkaAssignmentChanges.WithLabelValues(topic, event).Add(float64(len(ps)))
+
+		if event == assignmentAssigned {
+			metrics.KafkaAssignedPartitions.WithLabelValues(topic).Add(float64(len(ps)))
+			continue
+		}
+
+		metrics.KafkaAssignedPartitions.WithLabelValues(topic).Sub(float64(len(ps)))
+		for _, p := range ps {
+			metrics.KafkaConsumerLag.DeleteLabelValues(topic, strconv.Itoa(int(p)))
+		}
+	}
+}
+
+func toTopicPartitions(m map[string][]int32) []topicPartition {
+	var tps []topicPartition
+	for topic, ps := range m {
+		for _, p := range ps {
+			tps = append(tps, topicPartition{topic: topic, partition: p})
+		}
+	}
+	return tps
+}
@@ -0,0 +1,202 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrUnknownPartition = errors.New("unknown partition")
+
+// Replayer повторно обрабатывает записи топика отдельным клиентом без consumer group,
+// поэтому закоммиченные офсеты основной группы не меняются. Обработка идёт
+// до high watermark, зафиксированного в момент запуска.
+type Replayer struct {
+	common    []kgo.Opt
+	bindings  *Bindings
+	processor *processor
+	tracer    trace.Tracer
+	log       *slog.Logger
+}
+
+func NewReplayer(
+	common []kgo.Opt,
+	svc *service.Service,
+	bindings *Bindings,
+	tp trace.TracerProvider,
+	log *slog.Logger,
+) *Replayer {
+	return &Replayer{
+		common:   common,
+		bindings: bindings,
+		processor: &processor{
+			service:  svc,
+			bindings: bindings,
+			replay:   true,
+		},
+		tracer: newTracer(tp),
+		log:    log.With("component", "kafka_replayer"),
+	}
+}
+
+// Topic возвращает топик повторной обработки: запрошенный, если он покрыт
+// привязками, или единственный явно привязанный топик для пустого запроса.
+func (r *Replayer) Topic(requested string) (string, error) {
+	return r.bindings.replayTopic(requested)
+}
+
+func (r *Replayer) Replay(ctx context.Context, req model.ReplayRequest, report func(model.ReplayProgress)) error {
+	const op = "kafka.Replayer.Replay"
+
+	topic, err := r.Topic(req.Topic)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	partitions, err := r.bounds(ctx, topic, req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	progress := model.ReplayProgress{Partitions: partitions}
+	report(progress)
+	if len(partitions) == 0 {
+		return nil
+	}
+
+	offsets := make(map[int32]kgo.Offset, len(partitions))
+	index := make(map[int32]int, len(partitions))
+	for i, p := range partitions {
+		offsets[p.Partition] = kgo.NewOffset().At(p.StartOffset)
+		index[p.Partition] = i
+	}
+
+	// Контрольные записи транзакций тоже занимают офсеты, без них
+	// партиция могла бы не дойти до зафиксированного конца.
+	client, err := newClient(r.common, "replay",
+		kgo.ConsumePartitions(map[string]map[int32]kgo.Offset{topic: offsets}),
+		kgo.KeepControlRecords(),
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer client.Close()
+
+	remaining := len(partitions)
+	for remaining > 0 {
+		fetches := client.PollFetches(ctx)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if errs := fetches.Errors(); len(errs) > 0 {
+			return fmt.Errorf("%s: partition %d: %w", op, errs[0].Partition, errs[0].Err)
+		}
+
+		fetches.EachRecord(func(rec *kgo.Record) {
+			p := &progress.Partitions[index[rec.Partition]]
+			if p.CurrentOffset >= p.EndOffset {
+				return
+			}
+
+			if rec.Offset < p.EndOffset && !rec.Attrs.IsControl() {
+				recCtx, span := startProcessSpan(ctx, r.tracer, rec, "")
+				_, _, err := r.processor.process(recCtx, rec)
+				if err != nil {
+					progress.Failed++
+					r.log.DebugContext(recCtx, "replayed record failed", slog.Int64("offset", rec.Offset), slog.Any("error", err))
+				} else {
+					progress.Processed++
+				}
+				endSpan(span, err)
+			}
+
+			p.CurrentOffset = rec.Offset + 1
+			if p.CurrentOffset >= p.EndOffset {
+				remaining--
+				client.PauseFetchPartitions(map[string][]int32{topic: {rec.Partition}})
+			}
+		})
+
+		report(model.ReplayProgress{
+			Partitions: slices.Clone(progress.Partitions),
+			Processed:  progress.Processed,
+			Failed:     progress.Failed,
+		})
+	}
+
+	return nil
+}
+
+// bounds определяет для каждой партиции диапазон [start, end) повторной обработки.
+func (r *Replayer) bounds(ctx context.Context, topic string, req model.ReplayRequest) ([]model.ReplayPartitionProgress, error) {
+	client, err := newClient(r.common, "replay-admin")
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	adm := kadm.NewClient(client)
+
+	ends, err := adm.ListEndOffsets(ctx, topic)
+	if err == nil {
+		err = ends.Error()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list end offsets: %w", err)
+	}
+
+	logStarts, err := adm.ListStartOffsets(ctx, topic)
+	if err == nil {
+		err = logStarts.Error()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list start offsets: %w", err)
+	}
+
+	starts := make(map[int32]int64)
+	if req.FromTimestamp != nil {
+		listed, err := adm.ListOffsetsAfterMilli(ctx, req.FromTimestamp.UnixMilli(), topic)
+		if err == nil {
+			err = listed.Error()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list offsets after timestamp: %w", err)
+		}
+		listed.Each(func(o kadm.ListedOffset) { starts[o.Partition] = o.Offset })
+	} else {
+		for p, offset := range req.Offsets {
+			if _, ok := ends.Lookup(topic, p); !ok {
+				return nil, fmt.Errorf("%w: %d", ErrUnknownPartition, p)
+			}
+			starts[p] = offset
+		}
+	}
+
+	var partitions []model.ReplayPartitionProgress
+	for p, start := range starts {
+		end, _ := ends.Lookup(topic, p)
+		if ls, ok := logStarts.Lookup(topic, p); ok && start < ls.Offset {
+			start = ls.Offset
+		}
+		if start < 0 || start >= end.Offset {
+			continue
+		}
+		partitions = append(partitions, model.ReplayPartitionProgress{
+			Partition:     p,
+			StartOffset:   start,
+			CurrentOffset: start,
+			EndOffset:     end.Offset,
+		})
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].Partition < partitions[j].Partition })
+
+	return partitions, nil
+}
@@ -0,0 +1,200 @@
+package kafka
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/service/mocks"
+)
+
+const (
+	testTopic         = "records"
+	testResultsTopic  = "records.max"
+	testRejectedTopic = "records.rejected"
+)
+
+func newTestCluster(t *testing.T, partitions int32) []kgo.Opt {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(partitions, testTopic, testResultsTopic, testRejectedTopic))
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+
+	return []kgo.Opt{kgo.SeedBrokers(cluster.ListenAddrs()...)}
+}
+
+func produceTestRecords(t *testing.T, common []kgo.Opt, partition int32, recs ...model.ValueRecord) {
+	produceTopicRecords(t, common, testTopic, partition, recs...)
+}
+
+func produceTopicRecords(t *testing.T, common []kgo.Opt, topic string, partition int32, recs ...model.ValueRecord) {
+	client, err := kgo.NewClient(append(common, kgo.RecordPartitioner(kgo.ManualPartitioner()))...)
+	require.NoError(t, err)
+	defer client.Close()
+
+	for _, rec := range recs {
+		value, err := JSONCodec{}.Encode(rec)
+		require.NoError(t, err)
+		require.NoError(t, client.ProduceSync(context.Background(), &kgo.Record{
+			Topic:     topic,
+			Partition: partition,
+			Key:       []byte(rec.UUID),
+			Value:     value,
+		}).FirstErr())
+	}
+}
+
+// newTestBindings создаёт привязки с обязательными uuid, timestamp и value;
+// без аргументов — одну привязку testTopic.
+func newTestBindings(t *testing.T, cfgs ...config.TopicBinding) *Bindings {
+	if len(cfgs) == 0 {
+		cfgs = []config.TopicBinding{{Topic: testTopic}}
+	}
+	bindings, err := NewBindings(cfgs, config.ValidationConfig{RequiredFields: []string{"uuid", "timestamp", "value"}}, DefaultCodecs())
+	require.NoError(t, err)
+	return bindings
+}
+
+func testValueRecord(ts time.Time) model.ValueRecord {
+	return model.ValueRecord{UUID: uuid.New().String(), Timestamp: ts, Value: []int64{1, 2, 3}}
+}
+
+func TestReplayer_Replay(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	common := newTestCluster(t, 2)
+	now := time.Now().UTC()
+
+	produceTestRecords(t, common, 0, testValueRecord(now), testValueRecord(now), model.ValueRecord{})
+	produceTestRecords(t, common, 1, testValueRecord(now), testValueRecord(now))
+
+	var saved, withSource atomic.Int64
+	svc := service.New(logger, &mocks.MockMaxValueRepository{
+		SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+			saved.Add(1)
+			if rec.Source != nil {
+				withSource.Add(1)
+			}
+			return &model.MaxValueResult{UUID: rec.UUID}, nil
+		},
+	})
+	replayer := NewReplayer(common, svc, newTestBindings(t), noop.NewTracerProvider(), logger)
+
+	t.Run("From offsets", func(t *testing.T) {
+		saved.Store(0)
+		var last model.ReplayProgress
+
+		err := replayer.Replay(ctx, model.ReplayRequest{Offsets: map[int32]int64{0: 1, 1: 0}}, func(p model.ReplayProgress) {
+			last = p
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), last.Processed)
+		assert.Equal(t, int64(1), last.Failed)
+		assert.Equal(t, int64(3), saved.Load())
+		assert.Zero(t, withSource.Load(), "replayed records are saved without source so they are recomputed")
+		for _, p := range last.Partitions {
+			assert.Equal(t, p.EndOffset, p.CurrentOffset)
+		}
+	})
+
+	t.Run("From timestamp", func(t *testing.T) {
+		saved.Store(0)
+		var last model.ReplayProgress
+		from := now.Add(-time.Minute)
+
+		err := replayer.Replay(ctx, model.ReplayRequest{FromTimestamp: &from}, func(p model.ReplayProgress) {
+			last = p
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, last.Partitions, 2)
+		assert.Equal(t, int64(4), last.Processed)
+		assert.Equal(t, int64(1), last.Failed)
+	})
+
+	t.Run("Unknown partition", func(t *testing.T) {
+		err := replayer.Replay(ctx, model.ReplayRequest{Offsets: map[int32]int64{7: 0}}, func(model.ReplayProgress) {})
+
+		assert.ErrorIs(t, err, ErrUnknownPartition)
+	})
+}
+
+func TestReplayer_Topic(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	const otherTopic = "records.other"
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, testTopic, otherTopic))
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+	common := []kgo.Opt{kgo.SeedBrokers(cluster.ListenAddrs()...)}
+
+	now := time.Now().UTC()
+	produceTestRecords(t, common, 0, testValueRecord(now))
+	produceTopicRecords(t, common, otherTopic, 0, testValueRecord(now), testValueRecord(now))
+
+	var saved atomic.Int64
+	svc := service.New(logger, &mocks.MockMaxValueRepository{
+		SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+			saved.Add(1)
+			return &model.MaxValueResult{UUID: rec.UUID}, nil
+		},
+	})
+
+	t.Run("Requested topic is replayed", func(t *testing.T) {
+		saved.Store(0)
+		replayer := NewReplayer(common, svc, newTestBindings(t, config.TopicBinding{Topic: testTopic}, config.TopicBinding{TopicRegex: `^records\.other$`}), noop.NewTracerProvider(), logger)
+		var last model.ReplayProgress
+
+		err := replayer.Replay(ctx, model.ReplayRequest{Topic: otherTopic, Offsets: map[int32]int64{0: 0}}, func(p model.ReplayProgress) {
+			last = p
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), last.Processed)
+		assert.Equal(t, int64(2), saved.Load())
+	})
+
+	t.Run("Single bound topic by default", func(t *testing.T) {
+		replayer := NewReplayer(common, svc, newTestBindings(t), noop.NewTracerProvider(), logger)
+
+		topic, err := replayer.Topic("")
+
+		require.NoError(t, err)
+		assert.Equal(t, testTopic, topic)
+	})
+
+	t.Run("Unknown topic", func(t *testing.T) {
+		replayer := NewReplayer(common, svc, newTestBindings(t), noop.NewTracerProvider(), logger)
+
+		_, err := replayer.Topic(otherTopic)
+		assert.ErrorIs(t, err, service.ErrUnknownReplayTopic)
+
+		err = replayer.Replay(ctx, model.ReplayRequest{Topic: otherTopic, Offsets: map[int32]int64{0: 0}}, func(model.ReplayProgress) {})
+		assert.ErrorIs(t, err, service.ErrUnknownReplayTopic)
+	})
+
+	t.Run("Topic required with several bindings", func(t *testing.T) {
+		replayer := NewReplayer(common, svc, newTestBindings(t, config.TopicBinding{Topic: testTopic}, config.TopicBinding{Topic: otherTopic}), noop.NewTracerProvider(), logger)
+
+		_, err := replayer.Topic("")
+
+		assert.ErrorIs(t, err, service.ErrReplayTopicRequired)
+	})
+}
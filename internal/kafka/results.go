@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// ContentTypeResultv1 — формат сообщений в топике результатов.
+const ContentTypeResultv1 = "application/vnd.aggregator.max-value.v1+json"
+
+// Заголовки с позицией исходной записи во входном топике.
+const (
+	sourceTopicHeader     = "source-topic"
+	sourcePartitionHeader = "source-partition"
+	sourceOffsetHeader    = "source-offset"
+)
+
+// ResultsPublisher публикует сообщения outbox в топик результатов.
+type ResultsPublisher struct {
+	client *kgo.Client
+	log    *slog.Logger
+}
+
+func NewResultsPublisher(common []kgo.Opt, log *slog.Logger) (*ResultsPublisher, error) {
+	client, err := newClient(common, "results")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultsPublisher{
+		client: client,
+		log:    log.With("component", "kafka_results"),
+	}, nil
+}
+
+// Publish синхронно отправляет сообщения и возвращает первую ошибку доставки.
+func (p *ResultsPublisher) Publish(ctx context.Context, msgs []model.OutboxMessage) error {
+	records := make([]*kgo.Record, 0, len(msgs))
+	for _, msg := range msgs {
+		r, err := resultRecord(msg.Topic, msg.Result, msg.Source)
+		if err != nil {
+			return err
+		}
+		records = append(records, r)
+	}
+
+	results := p.client.ProduceSync(ctx, records...)
+	for _, res := range results {
+		if res.Err != nil {
+			metrics.KafkaProduceErrors.WithLabelValues(res.Record.Topic).Inc()
+		}
+	}
+	return results.FirstErr()
+}
+
+func (p *ResultsPublisher) Close() {
+	p.client.Close()
+}
+
+// resultRecord строит запись топика результатов с ключом uuid.
+func resultRecord(topic string, res model.MaxValueResult, src *model.RecordSource) (*kgo.Record, error) {
+	value, err := json.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := []kgo.RecordHeader{{Key: ContentTypeHeader, Value: []byte(ContentTypeResultv1)}}
+	if src != nil {
+		headers = append(headers,
+			kgo.RecordHeader{Key: sourceTopicHeader, Value: []byte(src.Topic)},
+			kgo.RecordHeader{Key: sourcePartitionHeader, Value: []byte(strconv.Itoa(int(src.Partition)))},
+			kgo.RecordHeader{Key: sourceOffsetHeader, Value: []byte(strconv.FormatInt(src.Offset, 10))},
+		)
+	}
+
+	return &kgo.Record{
+		Topic:   topic,
+		Key:     []byte(res.UUID),
+		Value:   value,
+		Headers: headers,
+	}, nil
+}
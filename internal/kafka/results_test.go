@@ -0,0 +1,55 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+func TestResultsPublisher_Publish(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	common := newTestCluster(t, 1)
+	publisher, err := NewResultsPublisher(common, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	result := model.MaxValueResult{UUID: "u-1", Version: 3, Timestamp: time.Now().UTC().Truncate(time.Millisecond), MaxValue: 42}
+	err = publisher.Publish(ctx, []model.OutboxMessage{{
+		ID:     1,
+		Topic:  testTopic,
+		Result: result,
+		Source: &model.RecordSource{Topic: "input", Partition: 2, Offset: 17},
+	}})
+	require.NoError(t, err)
+
+	client, err := kgo.NewClient(append(common, kgo.ConsumeTopics(testTopic))...)
+	require.NoError(t, err)
+	defer client.Close()
+
+	fetches := client.PollFetches(ctx)
+	require.NoError(t, fetches.Err())
+	records := fetches.Records()
+	require.Len(t, records, 1)
+
+	r := records[0]
+	assert.Equal(t, "u-1", string(r.Key))
+	assert.Equal(t, ContentTypeResultv1, headerValue(r, ContentTypeHeader))
+	assert.Equal(t, "input", headerValue(r, sourceTopicHeader))
+	assert.Equal(t, "2", headerValue(r, sourcePartitionHeader))
+	assert.Equal(t, "17", headerValue(r, sourceOffsetHeader))
+
+	var got model.MaxValueResult
+	require.NoError(t, json.Unmarshal(r.Value, &got))
+	assert.Equal(t, result, got)
+}
@@ -4,49 +4,135 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
 
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/twmb/franz-go/pkg/kadm"
 	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kgo"
-	"github.com/twmb/franz-go/pkg/kmsg"
 )
 
-// EnsureTopic создаёт Kafka topic, если он отсутствует, используя franz-go.
-func EnsureTopic(ctx context.Context, brokerAddr, topic string, partitions int) error {
-	client, err := kgo.NewClient(kgo.SeedBrokers(brokerAddr))
+// EnsureTopic создаёт топик с параметрами spec, если он отсутствует. Существующий
+// топик сверяется со spec: расхождения логируются, а при spec.ExpandPartitions
+// число партиций увеличивается до заданного.
+func EnsureTopic(ctx context.Context, common []kgo.Opt, topic string, spec config.KafkaTopicConfig, log *slog.Logger) error {
+	client, err := newClient(common, "admin")
 	if err != nil {
 		return fmt.Errorf("failed to create kafka client: %w", err)
 	}
 	defer client.Close()
+	adm := kadm.NewClient(client)
 
-	req := &kmsg.CreateTopicsRequest{
-		Topics: []kmsg.CreateTopicsRequestTopic{
-			{
-				Topic:             topic,
-				NumPartitions:     int32(partitions),
-				ReplicationFactor: 1,
-			},
-		},
-		TimeoutMillis: 5000,
+	configs := make(map[string]*string, len(spec.Configs))
+	for k, v := range spec.Configs {
+		configs[k] = kadm.StringPtr(v)
 	}
 
-	rawResp, err := client.Request(ctx, req)
+	_, err = adm.CreateTopic(ctx, int32(spec.Partitions), int16(spec.ReplicationFactor), configs, topic)
+	if err == nil {
+		log.Info("kafka topic created", slog.String("topic", topic), slog.Int("partitions", spec.Partitions))
+		return nil
+	}
+	if !errors.Is(err, kerr.TopicAlreadyExists) {
+		return fmt.Errorf("failed to create topic '%s': %w", topic, err)
+	}
+
+	state, err := describeTopic(ctx, adm, topic)
 	if err != nil {
-		return fmt.Errorf("failed to request topic creation: %w", err)
+		return fmt.Errorf("failed to describe topic '%s': %w", topic, err)
+	}
+
+	for _, d := range topicDrift(spec, state) {
+		log.Warn("kafka topic differs from configuration",
+			slog.String("topic", topic),
+			slog.String("setting", d.setting),
+			slog.String("want", d.want),
+			slog.String("have", d.have),
+		)
+	}
+
+	if spec.ExpandPartitions && state.partitions < spec.Partitions {
+		resp, err := adm.UpdatePartitions(ctx, spec.Partitions, topic)
+		if err == nil {
+			err = resp.Error()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to expand topic '%s' partitions: %w", topic, err)
+		}
+		log.Info("kafka topic partitions expanded",
+			slog.String("topic", topic),
+			slog.Int("from", state.partitions),
+			slog.Int("to", spec.Partitions),
+		)
 	}
 
-	resp, ok := rawResp.(*kmsg.CreateTopicsResponse)
+	return nil
+}
+
+// topicState — фактические параметры существующего топика.
+type topicState struct {
+	partitions        int
+	replicationFactor int
+	configs           map[string]string
+}
+
+func describeTopic(ctx context.Context, adm *kadm.Client, topic string) (topicState, error) {
+	details, err := adm.ListTopics(ctx, topic)
+	if err != nil {
+		return topicState{}, err
+	}
+	detail, ok := details[topic]
 	if !ok {
-		return fmt.Errorf("unexpected response type: %T", rawResp)
+		return topicState{}, kerr.UnknownTopicOrPartition
+	}
+	if detail.Err != nil {
+		return topicState{}, detail.Err
 	}
 
-	if len(resp.Topics) != 1 {
-		return fmt.Errorf("unexpected response length: %d", len(resp.Topics))
+	described, err := adm.DescribeTopicConfigs(ctx, topic)
+	if err != nil {
+		return topicState{}, err
+	}
+	rc, err := described.On(topic, nil)
+	if err != nil {
+		return topicState{}, err
 	}
-	topicResp := resp.Topics[0]
 
-	if err := kerr.ErrorForCode(topicResp.ErrorCode); err != nil && !errors.Is(err, kerr.TopicAlreadyExists) {
-		return fmt.Errorf("failed to create topic '%s': %w", topic, err)
+	state := topicState{
+		partitions:        len(detail.Partitions),
+		replicationFactor: detail.Partitions.NumReplicas(),
+		configs:           make(map[string]string, len(rc.Configs)),
+	}
+	for _, c := range rc.Configs {
+		if c.Value != nil {
+			state.configs[c.Key] = *c.Value
+		}
 	}
+	return state, nil
+}
 
-	return nil
+// drift — параметр топика, значение которого отличается от заданного.
+type drift struct {
+	setting string
+	want    string
+	have    string
+}
+
+// topicDrift сравнивает топик только по параметрам, заданным в spec.
+func topicDrift(spec config.KafkaTopicConfig, state topicState) []drift {
+	var out []drift
+	if spec.Partitions > 0 && state.partitions != spec.Partitions {
+		out = append(out, drift{setting: "partitions", want: fmt.Sprint(spec.Partitions), have: fmt.Sprint(state.partitions)})
+	}
+	if spec.ReplicationFactor > 0 && state.replicationFactor != spec.ReplicationFactor {
+		out = append(out, drift{setting: "replication.factor", want: fmt.Sprint(spec.ReplicationFactor), have: fmt.Sprint(state.replicationFactor)})
+	}
+	for _, key := range slices.Sorted(maps.Keys(spec.Configs)) {
+		if have := state.configs[key]; have != spec.Configs[key] {
+			out = append(out, drift{setting: key, want: spec.Configs[key], have: have})
+		}
+	}
+	return out
 }
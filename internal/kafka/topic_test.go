@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+)
+
+func TestEnsureTopic(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(3))
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+	common := []kgo.Opt{kgo.SeedBrokers(cluster.ListenAddrs()...)}
+
+	// Клиент кеширует метаданные, а тесту нужно видеть изменения сразу.
+	client, err := kgo.NewClient(append(common, kgo.MetadataMinAge(10*time.Millisecond))...)
+	require.NoError(t, err)
+	defer client.Close()
+	adm := kadm.NewClient(client)
+
+	spec := config.KafkaTopicConfig{
+		Partitions:        2,
+		ReplicationFactor: 1,
+		Configs:           map[string]string{"retention.ms": "3600000"},
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		require.NoError(t, EnsureTopic(ctx, common, "orders", spec, logger))
+
+		state, err := describeTopic(ctx, adm, "orders")
+		require.NoError(t, err)
+		assert.Equal(t, 2, state.partitions)
+		assert.Equal(t, "3600000", state.configs["retention.ms"])
+		assert.Empty(t, topicDrift(spec, state))
+	})
+
+	t.Run("Existing topic is not expanded by default", func(t *testing.T) {
+		grown := spec
+		grown.Partitions = 4
+		require.NoError(t, EnsureTopic(ctx, common, "orders", grown, logger))
+
+		state, err := describeTopic(ctx, adm, "orders")
+		require.NoError(t, err)
+		assert.Equal(t, 2, state.partitions)
+		assert.Equal(t, []drift{{setting: "partitions", want: "4", have: "2"}}, topicDrift(grown, state))
+	})
+
+	t.Run("Expand partitions", func(t *testing.T) {
+		grown := spec
+		grown.Partitions = 4
+		grown.ExpandPartitions = true
+		require.NoError(t, EnsureTopic(ctx, common, "orders", grown, logger))
+
+		require.Eventually(t, func() bool {
+			state, err := describeTopic(ctx, adm, "orders")
+			return err == nil && state.partitions == 4
+		}, 5*time.Second, 20*time.Millisecond)
+	})
+}
+
+func TestTopicDrift(t *testing.T) {
+	state := topicState{
+		partitions:        3,
+		replicationFactor: 1,
+		configs:           map[string]string{"cleanup.policy": "delete", "min.insync.replicas": "1"},
+	}
+
+	drifts := topicDrift(config.KafkaTopicConfig{
+		Partitions:        3,
+		ReplicationFactor: 3,
+		Configs:           map[string]string{"cleanup.policy": "compact", "min.insync.replicas": "1"},
+	}, state)
+
+	assert.Equal(t, []drift{
+		{setting: "replication.factor", want: "3", have: "1"},
+		{setting: "cleanup.policy", want: "compact", have: "delete"},
+	}, drifts)
+
+	assert.Empty(t, topicDrift(config.KafkaTopicConfig{ReplicationFactor: -1}, state))
+}
@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Pavel26ru/aggregator-service/internal/tracing"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/Pavel26ru/aggregator-service/internal/kafka"
+
+// newTracer возвращает трассировщик пакета из tp.
+func newTracer(tp trace.TracerProvider) trace.Tracer {
+	return tp.Tracer(tracerName)
+}
+
+// startSendSpan начинает спан отправки записи в topic; его контекст
+// попадает в заголовок traceparent. Если в ctx нет спана, родителем становится
+// traceparent из метаданных происхождения (см. tracing.WithMetadata).
+func startSendSpan(ctx context.Context, tracer trace.Tracer, topic string) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		if md, ok := tracing.FromContext(ctx); ok && md.TraceParent.IsValid() {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, md.TraceParent.SpanContext())
+		}
+	}
+	return tracer.Start(ctx, "send "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingDestinationName(topic),
+		),
+	)
+}
+
+// startProcessSpan извлекает происхождение записи (см. recordContext)
+// и начинает спан её обработки дочерним к спану продюсера.
+func startProcessSpan(ctx context.Context, tracer trace.Tracer, r *kgo.Record, group string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationTypeProcess,
+		semconv.MessagingDestinationName(r.Topic),
+		semconv.MessagingDestinationPartitionID(strconv.Itoa(int(r.Partition))),
+		semconv.MessagingKafkaOffset(int(r.Offset)),
+	}
+	if group != "" {
+		attrs = append(attrs, semconv.MessagingConsumerGroupName(group))
+	}
+
+	return tracer.Start(recordContext(ctx, r), "process "+r.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan завершает спан, отмечая ошибку, если она есть.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,91 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/service/mocks"
+	"github.com/Pavel26ru/aggregator-service/internal/tracing"
+)
+
+func TestTracing_ProduceConsume(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	common := newTestCluster(t, 1)
+
+	producer, err := NewProducer(common, testTopic, "ingest-1", JSONCodec{}, provider, logger)
+	require.NoError(t, err)
+	defer producer.Close()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "ingest")
+	require.NoError(t, producer.Produce(ctx, testValueRecord(time.Now().UTC())))
+	parent.End()
+
+	saved := make(chan trace.SpanContext, 1)
+	svc := service.New(logger, &mocks.MockMaxValueRepository{
+		SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+			saved <- trace.SpanContextFromContext(ctx)
+			return &model.MaxValueResult{UUID: rec.UUID}, nil
+		},
+	})
+	c, err := NewConsumer(common, config.KafkaConfig{Group: "test-group", MaxInFlight: 1}, 1, svc, newTestBindings(t), provider, logger)
+	require.NoError(t, err)
+	defer c.Close()
+	go func() { _ = c.Run(context.Background()) }()
+
+	var persisted trace.SpanContext
+	select {
+	case persisted = <-saved:
+	case <-time.After(10 * time.Second):
+		t.Fatal("record was not processed")
+	}
+	assert.Equal(t, parent.SpanContext().TraceID(), persisted.TraceID())
+
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	require.Eventually(t, func() bool {
+		for _, s := range recorder.Ended() {
+			byName[s.Name()] = s
+		}
+		return byName["send "+testTopic] != nil && byName["process "+testTopic] != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	send, process := byName["send "+testTopic], byName["process "+testTopic]
+	assert.Equal(t, parent.SpanContext().SpanID(), send.Parent().SpanID())
+	assert.Equal(t, send.SpanContext().SpanID(), process.Parent().SpanID())
+	assert.True(t, process.Parent().IsRemote())
+	assert.Equal(t, process.SpanContext().SpanID(), persisted.SpanID())
+}
+
+func TestTracing_SendSpanFromMetadata(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	producer, err := NewProducer(newTestCluster(t, 1), testTopic, "ingest-1", JSONCodec{}, provider, logger)
+	require.NoError(t, err)
+	defer producer.Close()
+
+	parent := tracing.NewTraceParent()
+	ctx := tracing.WithMetadata(context.Background(), tracing.Metadata{TraceParent: parent})
+	require.NoError(t, producer.Produce(ctx, testValueRecord(time.Now().UTC())))
+
+	require.Eventually(t, func() bool { return len(recorder.Ended()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	send := recorder.Ended()[0]
+	assert.Equal(t, "send "+testTopic, send.Name())
+	assert.Equal(t, parent.SpanContext(), send.Parent())
+	assert.Equal(t, parent.SpanContext().TraceID(), send.SpanContext().TraceID())
+}
@@ -0,0 +1,298 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrResultsTopicRequired = errors.New("exactly-once mode requires a results topic")
+
+// abortBackoff — пауза перед повтором пачки, которую не удалось сохранить,
+// чтобы недоступная база не превращалась в цикл прерванных транзакций.
+const abortBackoff = time.Second
+
+// Исходы транзакций для метрики kafka_transactions_total.
+const (
+	txnCommitted = "committed"
+	txnAborted   = "aborted"
+)
+
+// TransactConsumer — режим exactly-once. Каждая опрошенная пачка обрабатывается
+// в одной транзакции: результаты и отклонённые записи публикуются, а офсеты
+// коммитятся атомарно. Если во время пачки случился ребаланс или запись не
+// удалось сохранить, транзакция прерывается и пачка читается заново с последнего
+// коммита, так что в топике результатов (при чтении read_committed) нет повторов.
+// Запись, которую не удалось сохранить за cfg.MaxAttempts пачек, отклоняется
+// в транзакции, и пачка коммитится без неё.
+// Запись в базу в транзакцию не входит: повторная обработка снова выполняет upsert.
+type TransactConsumer struct {
+	session      *kgo.GroupTransactSession
+	group        string
+	tracer       trace.Tracer
+	log          *slog.Logger
+	pipeline     *pipeline
+	resultsTopic string
+	concurrency  int
+	maxAttempts  int
+
+	// failed отмечает, что текущую пачку нельзя коммитить.
+	failed atomic.Bool
+
+	// failures считает неудачные попытки сохранить записи пачки: после
+	// прерывания пачка перечитывается, и те же записи приходят снова.
+	mu       sync.Mutex
+	failures map[recordOffset]int
+}
+
+type recordOffset struct {
+	topicPartition
+	offset int64
+}
+
+func NewTransactConsumer(
+	common []kgo.Opt,
+	cfg config.KafkaConfig,
+	transactionalID string,
+	workers int,
+	svc *service.Service,
+	bindings *Bindings,
+	tp trace.TracerProvider,
+	log *slog.Logger,
+) (Consumer, error) {
+	if cfg.ResultsTopic == "" {
+		return nil, ErrResultsTopicRequired
+	}
+
+	c := &TransactConsumer{
+		group:        cfg.Group,
+		tracer:       newTracer(tp),
+		log:          log.With("component", "kafka_transact_consumer"),
+		resultsTopic: cfg.ResultsTopic,
+		concurrency:  max(workers, 1),
+		maxAttempts:  cfg.MaxAttempts,
+		failures:     make(map[recordOffset]int),
+	}
+	c.pipeline = &pipeline{
+		processor: &processor{
+			service:  svc,
+			bindings: bindings,
+		},
+		rejectedTopic: cfg.RejectedTopic,
+		produce: func(ctx context.Context, r *kgo.Record, promise func(*kgo.Record, error)) {
+			c.session.Produce(ctx, r, func(r *kgo.Record, err error) {
+				if err != nil {
+					c.failed.Store(true)
+				}
+				promise(r, err)
+			})
+		},
+		log: c.log,
+	}
+
+	opts := append(bindings.consumeOpts(),
+		kgo.ConsumerGroup(cfg.Group),
+		kgo.Balancers(kgo.CooperativeStickyBalancer()),
+		kgo.TransactionalID(transactionalID),
+		kgo.FetchIsolationLevel(kgo.ReadCommitted()),
+		kgo.RequireStableFetchOffsets(),
+		kgo.OnPartitionsAssigned(c.onAssigned),
+		kgo.OnPartitionsRevoked(c.onRevoked),
+		kgo.OnPartitionsLost(c.onLost),
+	)
+	session, err := kgo.NewGroupTransactSession(clientOpts(common, "transact", opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	c.session = session
+
+	return c, nil
+}
+
+// Run ведёт себя как FranzConsumer.Run: возвращает nil после отмены ctx или Close
+// и ошибку, обёрнутую в ErrConsumerFatal, если продолжать нельзя.
+func (c *TransactConsumer) Run(ctx context.Context) error {
+	for {
+		fetches := c.session.PollFetches(ctx)
+		if fetches.IsClientClosed() || ctx.Err() != nil {
+			return nil
+		}
+
+		if err := checkPollErrors(c.log, fetches); err != nil {
+			return err
+		}
+		if fetches.NumRecords() == 0 {
+			continue
+		}
+
+		if err := c.session.Begin(); err != nil {
+			return fmt.Errorf("%w: begin transaction: %w", ErrConsumerFatal, err)
+		}
+
+		commit := c.processBatch(ctx, fetches)
+
+		committed, err := c.session.End(ctx, commit)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("%w: end transaction: %w", ErrConsumerFatal, err)
+		}
+
+		if !committed {
+			metrics.KafkaTransactions.WithLabelValues(txnAborted).Inc()
+			c.log.Warn("transaction aborted, batch will be reprocessed", slog.Int("records", fetches.NumRecords()))
+
+			if c.failed.Load() {
+				select {
+				case <-ctx.Done():
+				case <-time.After(abortBackoff):
+				}
+			}
+			continue
+		}
+		metrics.KafkaTransactions.WithLabelValues(txnCommitted).Inc()
+		c.resetFailures()
+	}
+}
+
+// processBatch обрабатывает партиции пачки параллельно, записи внутри партиции —
+// по порядку. Возвращает TryAbort, если хотя бы одна запись не сохранена или
+// её результат не опубликован.
+func (c *TransactConsumer) processBatch(ctx context.Context, fetches kgo.Fetches) kgo.TransactionEndTry {
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, c.concurrency)
+	)
+	c.failed.Store(false)
+
+	fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+		if len(p.Records) == 0 {
+			return
+		}
+		observeLag(p)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, r := range p.Records {
+				if c.failed.Load() {
+					return
+				}
+
+				sem <- struct{}{}
+				err := c.transform(ctx, r)
+				<-sem
+
+				if err != nil {
+					c.failed.Store(true)
+					return
+				}
+			}
+		}()
+	})
+	wg.Wait()
+
+	// End тоже дожидается отправки, но решение о коммите нужно принять
+	// с учётом ошибок доставки.
+	if err := c.session.Client().Flush(ctx); err != nil {
+		return kgo.TryAbort
+	}
+	if c.failed.Load() {
+		return kgo.TryAbort
+	}
+	return kgo.TryCommit
+}
+
+// transform сохраняет запись и ставит её результат в транзакцию.
+// Ошибка доставки отмечается в c.failed асинхронно.
+func (c *TransactConsumer) transform(ctx context.Context, r *kgo.Record) (err error) {
+	ctx, span := startProcessSpan(ctx, c.tracer, r, c.group)
+	defer func() { endSpan(span, err) }()
+
+	res, err := c.pipeline.handle(ctx, r)
+	if err != nil {
+		if ctx.Err() != nil || !c.exhausted(r) {
+			c.log.ErrorContext(ctx, "failed to save max record", slog.Any("error", err))
+			return err
+		}
+		c.log.ErrorContext(ctx, "retries exhausted, rejecting record",
+			slog.String("topic", r.Topic),
+			slog.Int("partition", int(r.Partition)),
+			slog.Int64("offset", r.Offset),
+			slog.Any("error", err),
+		)
+		c.pipeline.reject(ctx, r, reasonRetriesExhausted)
+		return nil
+	}
+	if res == nil {
+		return nil
+	}
+
+	out, err := resultRecord(c.resultsTopic, *res, &model.RecordSource{Topic: r.Topic, Partition: r.Partition, Offset: r.Offset})
+	if err != nil {
+		return err
+	}
+
+	c.session.Produce(ctx, out, func(_ *kgo.Record, err error) {
+		if err != nil {
+			c.failed.Store(true)
+			metrics.KafkaProduceErrors.WithLabelValues(c.resultsTopic).Inc()
+			c.log.ErrorContext(ctx, "failed to produce result", slog.Any("error", err))
+		}
+	})
+	return nil
+}
+
+// exhausted учитывает неудачную попытку сохранить запись и сообщает,
+// исчерпаны ли cfg.MaxAttempts. Счётчик сохраняется до коммита пачки: её могут
+// прервать из-за другой партиции, и отклонённая запись придёт снова.
+func (c *TransactConsumer) exhausted(r *kgo.Record) bool {
+	if c.maxAttempts <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := recordOffset{topicPartition{topic: r.Topic, partition: r.Partition}, r.Offset}
+	c.failures[key]++
+	return c.failures[key] >= c.maxAttempts
+}
+
+// resetFailures забывает попытки после коммита: все записи пачки
+// сохранены или отклонены.
+func (c *TransactConsumer) resetFailures() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.failures)
+}
+
+func (c *TransactConsumer) onAssigned(_ context.Context, _ *kgo.Client, assigned map[string][]int32) {
+	recordAssignment(c.log, assignmentAssigned, assigned)
+}
+
+// Незавершённую транзакцию при отзыве или потере партиций прерывает сама
+// GroupTransactSession, здесь изменения только учитываются.
+func (c *TransactConsumer) onRevoked(_ context.Context, _ *kgo.Client, revoked map[string][]int32) {
+	recordAssignment(c.log, assignmentRevoked, revoked)
+}
+
+func (c *TransactConsumer) onLost(_ context.Context, _ *kgo.Client, lost map[string][]int32) {
+	recordAssignment(c.log, assignmentLost, lost)
+}
+
+func (c *TransactConsumer) Close() {
+	c.session.Close()
+}
@@ -0,0 +1,200 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/service/mocks"
+)
+
+func TestTransactConsumer_ExactlyOnce(t *testing.T) {
+	const perPartition = 10
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := config.KafkaConfig{Topic: testTopic, Group: "test-group", ResultsTopic: testResultsTopic}
+
+	setup := func(t *testing.T) []kgo.Opt {
+		common := newTestCluster(t, 2)
+		now := time.Now().UTC()
+		for p := int32(0); p < 2; p++ {
+			recs := make([]model.ValueRecord, perPartition)
+			for i := range recs {
+				recs[i] = testValueRecord(now)
+			}
+			produceTestRecords(t, common, p, recs...)
+		}
+		return common
+	}
+
+	start := func(t *testing.T, common []kgo.Opt, cfg config.KafkaConfig, txnID string, repo *mocks.MockMaxValueRepository) (Consumer, <-chan error) {
+		c, err := NewTransactConsumer(common, cfg, txnID, 2, service.New(logger, repo), newTestBindings(t), noop.NewTracerProvider(), logger)
+		require.NoError(t, err)
+
+		done := make(chan error, 1)
+		go func() { done <- c.Run(context.Background()) }()
+		return c, done
+	}
+
+	t.Run("Worker killed mid-batch", func(t *testing.T) {
+		common := setup(t)
+
+		var (
+			saved    atomic.Int64
+			midBatch = make(chan struct{})
+			release  = make(chan struct{})
+			once     sync.Once
+		)
+		repo := &mocks.MockMaxValueRepository{
+			SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+				if saved.Add(1) == 3 {
+					once.Do(func() { close(midBatch) })
+					<-release
+				}
+				return &model.MaxValueResult{UUID: rec.UUID, Version: 1, Timestamp: rec.Timestamp, MaxValue: rec.MaxValue}, nil
+			},
+		}
+
+		// Первый воркер успевает сохранить и поставить в транзакцию часть пачки
+		// и останавливается, не завершив её.
+		first, firstDone := start(t, common, cfg, "worker-0", repo)
+		select {
+		case <-midBatch:
+		case <-time.After(10 * time.Second):
+			t.Fatal("first worker did not start processing")
+		}
+		first.Close()
+		close(release)
+		<-firstDone
+
+		// Перезапущенный воркер с тем же transactional id отсекает незавершённую
+		// транзакцию и обрабатывает пачку заново.
+		second, _ := start(t, common, cfg, "worker-0", repo)
+		defer second.Close()
+
+		results := readCommittedResults(t, common, 2*perPartition)
+		assertNoDuplicates(t, results)
+		assert.Len(t, results, 2*perPartition)
+	})
+
+	t.Run("Persist failure aborts the batch", func(t *testing.T) {
+		common := setup(t)
+
+		var calls atomic.Int64
+		repo := &mocks.MockMaxValueRepository{
+			SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+				if calls.Add(1) == 5 {
+					return nil, errors.New("connection reset")
+				}
+				return &model.MaxValueResult{UUID: rec.UUID, Version: 1, Timestamp: rec.Timestamp, MaxValue: rec.MaxValue}, nil
+			},
+		}
+
+		c, _ := start(t, common, cfg, "worker-0", repo)
+		defer c.Close()
+
+		results := readCommittedResults(t, common, 2*perPartition)
+		assertNoDuplicates(t, results)
+		assert.Len(t, results, 2*perPartition)
+		assert.Greater(t, calls.Load(), int64(2*perPartition), "failed batch must be reprocessed")
+	})
+
+	t.Run("Poison records are rejected in the transaction", func(t *testing.T) {
+		common := setup(t)
+		cfg := cfg
+		cfg.RejectedTopic = testRejectedTopic
+		cfg.MaxAttempts = 2
+
+		var (
+			mu    sync.Mutex
+			seen  = map[string]int{}
+			count atomic.Int64
+		)
+		repo := &mocks.MockMaxValueRepository{
+			SaveMaxFunc: func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				// Первая запись каждой партиции отвергается хранилищем, вторая
+				// не сохраняется никогда.
+				if _, ok := seen[rec.UUID]; !ok {
+					seen[rec.UUID] = int(count.Add(1))
+				}
+				switch seen[rec.UUID] {
+				case 1, 2:
+					return nil, fmt.Errorf("%w: value out of range", repository.ErrInvalidRecord)
+				case 3, 4:
+					return nil, errors.New("connection refused")
+				}
+				return &model.MaxValueResult{UUID: rec.UUID, Version: 1, Timestamp: rec.Timestamp, MaxValue: rec.MaxValue}, nil
+			},
+		}
+
+		c, _ := start(t, common, cfg, "worker-0", repo)
+		defer c.Close()
+
+		results := readCommittedResults(t, common, 2*perPartition-4)
+		assertNoDuplicates(t, results)
+		assert.Len(t, results, 2*perPartition-4)
+
+		reasons := map[string]int{}
+		for _, r := range readCommitted(t, common, testRejectedTopic, 4) {
+			for _, h := range r.Headers {
+				if h.Key == rejectReasonHeader {
+					reasons[string(h.Value)]++
+				}
+			}
+		}
+		assert.Equal(t, map[string]int{reasonInvalidRecord: 2, reasonRetriesExhausted: 2}, reasons)
+	})
+}
+
+// readCommittedResults читает топик результатов в режиме read_committed, пока не
+// получит want записей, и ещё немного после, чтобы заметить лишние.
+func readCommittedResults(t *testing.T, common []kgo.Opt, want int) []*kgo.Record {
+	return readCommitted(t, common, testResultsTopic, want)
+}
+
+func readCommitted(t *testing.T, common []kgo.Opt, topic string, want int) []*kgo.Record {
+	client, err := kgo.NewClient(append(common,
+		kgo.ConsumeTopics(topic),
+		kgo.FetchIsolationLevel(kgo.ReadCommitted()),
+	)...)
+	require.NoError(t, err)
+	defer client.Close()
+
+	var records []*kgo.Record
+	deadline := time.Now().Add(15 * time.Second)
+	for len(records) < want && time.Now().Before(deadline) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		records = append(records, client.PollFetches(ctx).Records()...)
+		cancel()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	return append(records, client.PollFetches(ctx).Records()...)
+}
+
+func assertNoDuplicates(t *testing.T, records []*kgo.Record) {
+	seen := make(map[string]int)
+	for _, r := range records {
+		seen[string(r.Key)]++
+	}
+	for key, n := range seen {
+		assert.Equal(t, 1, n, "result for %s published %d times", key, n)
+	}
+}
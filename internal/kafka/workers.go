@@ -0,0 +1,230 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Пауза перед повторной обработкой записи, которую не удалось сохранить,
+// растёт вдвое с каждой попыткой до retryMaxBackoff.
+const (
+	retryBackoff    = 100 * time.Millisecond
+	retryMaxBackoff = 5 * time.Second
+)
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// partitionWorker — горутина, последовательно обрабатывающая записи одной партиции.
+// detached закрывается, когда партиция отзывается или теряется: воркер перестаёт
+// повторять запись, которую не удалось сохранить.
+type partitionWorker struct {
+	records  chan *kgo.Record
+	cancel   context.CancelFunc
+	detached chan struct{}
+	done     chan struct{}
+}
+
+// workerPool обрабатывает записи каждой партиции в отдельной горутине,
+// сохраняя порядок внутри партиции (а значит и по ключу).
+// concurrency ограничивает число одновременно обрабатываемых записей,
+// inFlight — число записей, полученных из Kafka, но ещё не обработанных:
+// при его исчерпании dispatch блокирует цикл опроса.
+//
+// Ошибка process означает, что запись не сохранена и её офсет не помечен.
+// Воркер повторяет её с растущей паузой, не переходя к следующим записям партиции,
+// иначе их офсеты закоммитили бы и её. После maxAttempts неудачных попыток
+// (0 — без ограничения) запись передаётся exhausted, и партиция продолжает
+// обработку. Если партицию отзывают во время повторов, оставшиеся записи
+// отбрасываются и будут прочитаны заново с закоммиченного офсета.
+type workerPool struct {
+	process     func(ctx context.Context, r *kgo.Record) error
+	exhausted   func(ctx context.Context, r *kgo.Record, err error)
+	concurrency chan struct{}
+	inFlight    chan struct{}
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+
+	mu      sync.Mutex
+	workers map[topicPartition]*partitionWorker
+}
+
+func newWorkerPool(
+	concurrency, maxInFlight, maxAttempts int,
+	process func(ctx context.Context, r *kgo.Record) error,
+	exhausted func(ctx context.Context, r *kgo.Record, err error),
+) *workerPool {
+	return &workerPool{
+		process:     process,
+		exhausted:   exhausted,
+		concurrency: make(chan struct{}, max(concurrency, 1)),
+		inFlight:    make(chan struct{}, max(maxInFlight, 1)),
+		maxAttempts: maxAttempts,
+		backoff:     retryBackoff,
+		maxBackoff:  retryMaxBackoff,
+		workers:     make(map[topicPartition]*partitionWorker),
+	}
+}
+
+// dispatch передаёт записи партиции её воркеру. Возвращает false, если ctx
+// отменён или партицию отозвали до того, как все записи были переданы.
+func (p *workerPool) dispatch(ctx context.Context, tp topicPartition, records []*kgo.Record) bool {
+	p.mu.Lock()
+	w := p.worker(ctx, tp)
+	p.mu.Unlock()
+
+	for _, r := range records {
+		// Слот ждём без блокировки, чтобы drain и abort не вставали
+		// за переполненным воркером.
+		select {
+		case p.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return false
+		}
+		if !p.send(tp, w, r) {
+			<-p.inFlight
+			return false
+		}
+	}
+	return true
+}
+
+// send передаёт запись воркеру, если он ещё обслуживает партицию: под p.mu
+// drain и abort не закроют канал посреди отправки. Ёмкость канала равна
+// лимиту inFlight, поэтому после захвата слота отправка не блокируется.
+func (p *workerPool) send(tp topicPartition, w *partitionWorker, r *kgo.Record) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.workers[tp] != w {
+		return false
+	}
+	w.records <- r
+	return true
+}
+
+// worker возвращает воркер партиции, запуская его при первом обращении.
+// Вызывается под p.mu.
+func (p *workerPool) worker(ctx context.Context, tp topicPartition) *partitionWorker {
+	if w, ok := p.workers[tp]; ok {
+		return w
+	}
+
+	// Отмена ctx опроса не прерывает уже полученные записи: их дообработает
+	// drain при отзыве партиций, прервёт только abort.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	w := &partitionWorker{
+		records:  make(chan *kgo.Record, cap(p.inFlight)),
+		cancel:   cancel,
+		detached: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	p.workers[tp] = w
+
+	go func() {
+		defer close(w.done)
+		defer cancel()
+
+		failed := false
+		for r := range w.records {
+			// После abort или несохранённой записи оставшиеся записи только освобождают слоты.
+			if ctx.Err() == nil && !failed {
+				failed = !p.processWithRetry(ctx, w, r)
+			}
+			<-p.inFlight
+		}
+	}()
+
+	return w
+}
+
+// processWithRetry обрабатывает запись, повторяя попытки до успеха или исчерпания
+// maxAttempts. Возвращает false, если партицию отозвали или обработку прервали раньше.
+func (p *workerPool) processWithRetry(ctx context.Context, w *partitionWorker, r *kgo.Record) bool {
+	backoff := p.backoff
+	for attempt := 1; ; attempt++ {
+		p.concurrency <- struct{}{}
+		err := p.process(ctx, r)
+		<-p.concurrency
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		if p.maxAttempts > 0 && attempt >= p.maxAttempts {
+			p.exhausted(ctx, r, err)
+			return true
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-w.detached:
+			timer.Stop()
+			return false
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		}
+		backoff = min(2*backoff, p.maxBackoff)
+	}
+}
+
+// drain дожидается обработки уже переданных записей указанных партиций
+// и останавливает их воркеры.
+func (p *workerPool) drain(tps []topicPartition) {
+	p.wait(p.detach(tps, false))
+}
+
+// abort прерывает обработку указанных партиций: текущая запись получает
+// отменённый контекст, оставшиеся в очереди отбрасываются.
+func (p *workerPool) abort(tps []topicPartition) {
+	p.wait(p.detach(tps, true))
+}
+
+// stop дожидается обработки всех переданных записей и останавливает воркеры.
+func (p *workerPool) stop() {
+	p.mu.Lock()
+	tps := make([]topicPartition, 0, len(p.workers))
+	for tp := range p.workers {
+		tps = append(tps, tp)
+	}
+	p.mu.Unlock()
+
+	p.drain(tps)
+}
+
+func (p *workerPool) detach(tps []topicPartition, cancel bool) []*partitionWorker {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	detached := make([]*partitionWorker, 0, len(tps))
+	for _, tp := range tps {
+		w, ok := p.workers[tp]
+		if !ok {
+			continue
+		}
+		delete(p.workers, tp)
+
+		if cancel {
+			w.cancel()
+		}
+		close(w.detached)
+		close(w.records)
+		detached = append(detached, w)
+	}
+	return detached
+}
+
+func (p *workerPool) wait(workers []*partitionWorker) {
+	for _, w := range workers {
+		<-w.done
+	}
+}
@@ -0,0 +1,206 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestWorkerPool_PreservesPartitionOrder(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seen = map[int32][]int64{}
+	)
+	pool := newWorkerPool(4, 16, 0, func(_ context.Context, r *kgo.Record) error {
+		mu.Lock()
+		seen[r.Partition] = append(seen[r.Partition], r.Offset)
+		mu.Unlock()
+		return nil
+	}, nil)
+
+	ctx := context.Background()
+	for batch := 0; batch < 10; batch++ {
+		for p := int32(0); p < 3; p++ {
+			records := make([]*kgo.Record, 5)
+			for i := range records {
+				records[i] = &kgo.Record{Partition: p, Offset: int64(batch*5 + i)}
+			}
+			assert.True(t, pool.dispatch(ctx, topicPartition{topic: testTopic, partition: p}, records))
+		}
+	}
+	pool.stop()
+
+	for p := int32(0); p < 3; p++ {
+		assert.Len(t, seen[p], 50)
+		for i, off := range seen[p] {
+			assert.Equal(t, int64(i), off)
+		}
+	}
+}
+
+func TestWorkerPool_BoundsInFlight(t *testing.T) {
+	release := make(chan struct{})
+	var active, peak atomic.Int32
+
+	pool := newWorkerPool(10, 2, 0, func(_ context.Context, _ *kgo.Record) error {
+		n := active.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		active.Add(-1)
+		return nil
+	}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	records := []*kgo.Record{{Partition: 0}, {Partition: 1}, {Partition: 2}}
+	ok := pool.dispatch(ctx, topicPartition{topic: testTopic, partition: 0}, records)
+	assert.False(t, ok, "third record must wait for a free in-flight slot")
+
+	close(release)
+	pool.stop()
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestWorkerPool_RetriesFailedRecord(t *testing.T) {
+	errPersist := errors.New("persist failed")
+
+	t.Run("Retries until processed", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			attempts = map[int64]int{}
+			seen     []int64
+		)
+		pool := newWorkerPool(1, 4, 0, func(_ context.Context, r *kgo.Record) error {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts[r.Offset]++
+			if r.Offset == 1 && attempts[r.Offset] < 3 {
+				return errPersist
+			}
+			seen = append(seen, r.Offset)
+			return nil
+		}, nil)
+		pool.backoff = time.Millisecond
+
+		records := []*kgo.Record{{Offset: 0}, {Offset: 1}, {Offset: 2}}
+		assert.True(t, pool.dispatch(context.Background(), topicPartition{topic: testTopic}, records))
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(seen) == 3
+		}, time.Second, time.Millisecond)
+		pool.stop()
+
+		assert.Equal(t, []int64{0, 1, 2}, seen)
+		assert.Equal(t, 3, attempts[1])
+	})
+
+	t.Run("Drain skips the rest of the partition", func(t *testing.T) {
+		var processed atomic.Int64
+		failing := make(chan struct{})
+		var once sync.Once
+		pool := newWorkerPool(1, 4, 0, func(_ context.Context, r *kgo.Record) error {
+			if r.Offset == 1 {
+				once.Do(func() { close(failing) })
+				return errPersist
+			}
+			processed.Add(1)
+			return nil
+		}, nil)
+		pool.backoff = time.Hour
+
+		tp := topicPartition{topic: testTopic}
+		records := []*kgo.Record{{Offset: 0}, {Offset: 1}, {Offset: 2}}
+		assert.True(t, pool.dispatch(context.Background(), tp, records))
+
+		<-failing
+		pool.drain([]topicPartition{tp})
+		assert.Equal(t, int64(1), processed.Load(), "records after the failed one must not be processed")
+	})
+}
+
+func TestWorkerPool_RejectsExhaustedRecord(t *testing.T) {
+	errPersist := errors.New("value out of range")
+
+	var (
+		mu        sync.Mutex
+		attempts  = map[int64]int{}
+		seen      []int64
+		exhausted []int64
+	)
+	pool := newWorkerPool(1, 4, 3, func(_ context.Context, r *kgo.Record) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[r.Offset]++
+		if r.Offset == 1 {
+			return errPersist
+		}
+		seen = append(seen, r.Offset)
+		return nil
+	}, func(_ context.Context, r *kgo.Record, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		assert.ErrorIs(t, err, errPersist)
+		exhausted = append(exhausted, r.Offset)
+	})
+	pool.backoff = time.Millisecond
+
+	records := []*kgo.Record{{Offset: 0}, {Offset: 1}, {Offset: 2}}
+	assert.True(t, pool.dispatch(context.Background(), topicPartition{topic: testTopic}, records))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(seen) == 2
+	}, time.Second, time.Millisecond)
+	pool.stop()
+
+	assert.Equal(t, []int64{0, 2}, seen, "partition must move past the failing record")
+	assert.Equal(t, []int64{1}, exhausted)
+	assert.Equal(t, 3, attempts[1])
+}
+
+func TestWorkerPool_DrainWhileDispatchWaits(t *testing.T) {
+	release := make(chan struct{})
+	pool := newWorkerPool(1, 1, 0, func(ctx context.Context, _ *kgo.Record) error {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	}, nil)
+
+	tp := topicPartition{topic: testTopic}
+	dispatched := make(chan bool)
+	go func() {
+		dispatched <- pool.dispatch(context.Background(), tp, []*kgo.Record{{Offset: 0}, {Offset: 1}})
+	}()
+
+	// dispatch ждёт слот для второй записи; abort не должен вставать за ним.
+	aborted := make(chan struct{})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		pool.abort([]topicPartition{tp})
+		close(aborted)
+	}()
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("abort blocked behind dispatch")
+	}
+	assert.False(t, <-dispatched, "records of a revoked partition must not be dispatched")
+	close(release)
+	pool.stop()
+}
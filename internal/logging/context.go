@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Pavel26ru/aggregator-service/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// contextHandler добавляет к записям лога сведения о трассировке из ctx,
+// переданного в методы *Context логгера. Идентификаторы текущего спана
+// важнее полученных из заголовков записи.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	md, _ := tracing.FromContext(ctx)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		md.TraceParent = tracing.FromSpanContext(sc)
+	}
+	r.AddAttrs(md.LogAttrs()...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
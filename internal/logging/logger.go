@@ -24,5 +24,5 @@ func SetupLogger(env string) *slog.Logger {
 		})
 	}
 
-	return slog.New(handler)
+	return slog.New(contextHandler{handler})
 }
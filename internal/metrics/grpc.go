@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// GRPC — метрики gRPC-сервера.
+type GRPC struct {
+	duration *prometheus.HistogramVec
+}
+
+// NewGRPC создаёт метрики и регистрирует их в reg.
+func NewGRPC(reg prometheus.Registerer) *GRPC {
+	m := &GRPC{
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "grpc_server_handling_seconds",
+				Help:    "Duration of gRPC calls by method and status code.",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"method", "type", "code"},
+		),
+	}
+	reg.MustRegister(m.duration)
+	return m
+}
+
+func (m *GRPC) UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.observe(info.FullMethod, "unary", err, start)
+	return resp, err
+}
+
+func (m *GRPC) StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	m.observe(info.FullMethod, "stream", err, start)
+	return err
+}
+
+func (m *GRPC) observe(method, typ string, err error, start time.Time) {
+	m.duration.WithLabelValues(method, typ, status.Code(err).String()).Observe(time.Since(start).Seconds())
+}
@@ -5,45 +5,110 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-var (
-	ReqDuration = prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "http_request_duration_seconds",
-			Help:    "Duration of HTTP requests.",
-			Buckets: prometheus.DefBuckets,
-		},
-		[]string{"method", "path", "status"},
-	)
-)
+// UnmatchedRoute — значение метки path для запросов, не попавших ни в один
+// маршрут: иначе сканеры и опечатки порождали бы новые серии на каждый путь.
+const UnmatchedRoute = "unmatched"
+
+// sizeBuckets — от 64 байт до 16 МБ.
+var sizeBuckets = prometheus.ExponentialBuckets(64, 4, 10)
 
-func init() {
-	prometheus.MustRegister(ReqDuration)
+// HTTP — метрики REST API. Метка path содержит шаблон маршрута chi
+// (/max/{uuid}/revisions), а не фактический путь.
+type HTTP struct {
+	duration     *prometheus.HistogramVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+	inFlight     prometheus.Gauge
 }
 
-func Middleware(next http.Handler) http.Handler {
+// NewHTTP создаёт метрики и регистрирует их в reg.
+func NewHTTP(reg prometheus.Registerer) *HTTP {
+	m := &HTTP{
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_duration_seconds",
+				Help:    "Duration of HTTP requests.",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"method", "path", "status"},
+		),
+		requestSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_size_bytes",
+				Help:    "Size of HTTP request bodies.",
+				Buckets: sizeBuckets,
+			},
+			[]string{"method", "path"},
+		),
+		responseSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_response_size_bytes",
+				Help:    "Size of HTTP response bodies.",
+				Buckets: sizeBuckets,
+			},
+			[]string{"method", "path", "status"},
+		),
+		inFlight: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "http_requests_in_flight",
+				Help: "Number of HTTP requests currently being served.",
+			},
+		),
+	}
+	reg.MustRegister(m.duration, m.requestSize, m.responseSize, m.inFlight)
+	return m
+}
+
+// Middleware должен стоять внутри роутера chi (r.Use), иначе шаблон маршрута
+// неизвестен и все запросы попадут в UnmatchedRoute.
+func (m *HTTP) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
 
 		ww := &responseWriter{ResponseWriter: w, statusCode: 200}
 		next.ServeHTTP(ww, r)
 
-		ReqDuration.WithLabelValues(
-			r.Method,
-			r.URL.Path,
-			strconv.Itoa(ww.statusCode),
-		).Observe(time.Since(start).Seconds())
+		route := routePattern(r)
+		status := strconv.Itoa(ww.statusCode)
+
+		m.duration.WithLabelValues(r.Method, route, status).Observe(time.Since(start).Seconds())
+		if r.ContentLength >= 0 {
+			m.requestSize.WithLabelValues(r.Method, route).Observe(float64(r.ContentLength))
+		}
+		m.responseSize.WithLabelValues(r.Method, route, status).Observe(float64(ww.written))
 	})
 }
 
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return UnmatchedRoute
+	}
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		return pattern
+	}
+	return UnmatchedRoute
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	written    int
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.written += n
+	return n, err
+}
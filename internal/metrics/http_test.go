@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTP_Middleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m := NewHTTP(reg)
+
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/max/{uuid}/revisions", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("[]"))
+	})
+	r.Post("/admin/replays", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	for _, path := range []string{"/max/a/revisions", "/max/b/revisions", "/.env", "/wp-login.php"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/admin/replays", strings.NewReader(`{"from":"x"}`)))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	byName := make(map[string]map[string]uint64)
+	for _, f := range families {
+		series := make(map[string]uint64)
+		for _, metric := range f.GetMetric() {
+			var labels []string
+			for _, l := range metric.GetLabel() {
+				labels = append(labels, l.GetValue())
+			}
+			if h := metric.GetHistogram(); h != nil {
+				series[strings.Join(labels, " ")] = h.GetSampleCount()
+			}
+		}
+		byName[f.GetName()] = series
+	}
+
+	t.Run("Route pattern labels", func(t *testing.T) {
+		assert.Equal(t, map[string]uint64{
+			"GET /max/{uuid}/revisions 200": 2,
+			"GET unmatched 404":             2,
+			"POST /admin/replays 202":       1,
+		}, byName["http_request_duration_seconds"])
+	})
+
+	t.Run("Sizes", func(t *testing.T) {
+		assert.Equal(t, uint64(2), byName["http_response_size_bytes"]["GET /max/{uuid}/revisions 200"])
+		assert.Equal(t, uint64(1), byName["http_request_size_bytes"]["POST /admin/replays"])
+	})
+
+	t.Run("In flight returns to zero", func(t *testing.T) {
+		assert.Equal(t, float64(0), testutil.ToFloat64(m.inFlight))
+	})
+}
@@ -0,0 +1,13 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	RecordsRejected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ingestion_records_rejected_total",
+			Help: "Number of incoming records rejected before storage, by reason.",
+		},
+		[]string{"reason"},
+	)
+)
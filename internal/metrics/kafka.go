@@ -0,0 +1,77 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	KafkaRecordsConsumed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_records_consumed_total",
+			Help: "Number of records fetched from Kafka.",
+		},
+		[]string{"topic", "partition"},
+	)
+
+	KafkaRecordsDecoded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_records_decoded_total",
+			Help: "Number of records successfully decoded.",
+		},
+		[]string{"topic", "partition"},
+	)
+
+	KafkaRecordsFailed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_records_failed_total",
+			Help: "Number of records that failed processing, by stage.",
+		},
+		[]string{"topic", "partition", "stage"},
+	)
+
+	KafkaConsumerLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kafka_consumer_lag",
+			Help: "Records between the last processed offset and the partition high watermark.",
+		},
+		[]string{"topic", "partition"},
+	)
+
+	KafkaAssignedPartitions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kafka_assigned_partitions",
+			Help: "Number of partitions currently assigned to this consumer.",
+		},
+		[]string{"topic"},
+	)
+
+	KafkaAssignmentChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_partition_assignment_changes_total",
+			Help: "Number of partitions assigned, revoked or lost in rebalances.",
+		},
+		[]string{"topic", "event"},
+	)
+
+	KafkaTransactions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_transactions_total",
+			Help: "Number of exactly-once transactions by outcome.",
+		},
+		[]string{"outcome"},
+	)
+
+	KafkaProduceErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_produce_errors_total",
+			Help: "Number of records that failed to be produced.",
+		},
+		[]string{"topic"},
+	)
+
+	PipelineLatency = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "pipeline_end_to_end_latency_seconds",
+			Help:    "Time from ValueRecord timestamp to the record being persisted; discarded and replayed records are not observed.",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+		},
+	)
+)
@@ -0,0 +1,19 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	OutboxPublished = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "outbox_messages_published_total",
+			Help: "Number of outbox messages published to the results topic.",
+		},
+	)
+
+	OutboxPublishErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "outbox_publish_errors_total",
+			Help: "Number of failed outbox publish attempts.",
+		},
+	)
+)
@@ -0,0 +1,13 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	RateLimitThrottled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limit_throttled_total",
+			Help: "Number of requests rejected by the rate limiter.",
+		},
+		[]string{"transport", "class"},
+	)
+)
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// NewRegistry создаёт реестр, который отдаёт /metrics: метрики рантайма Go,
+// процесса и коллекторы пакета. Метрики HTTP, gRPC и клиентов Kafka
+// регистрируются в нём их конструкторами.
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+
+		RecordsRejected,
+		KafkaRecordsConsumed,
+		KafkaRecordsDecoded,
+		KafkaRecordsFailed,
+		KafkaConsumerLag,
+		KafkaAssignedPartitions,
+		KafkaAssignmentChanges,
+		KafkaTransactions,
+		KafkaProduceErrors,
+		PipelineLatency,
+		OutboxPublished,
+		OutboxPublishErrors,
+		RateLimitThrottled,
+		UpsertDiscarded,
+	)
+	return reg
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegistry(t *testing.T) {
+	reg := NewRegistry()
+	NewHTTP(reg)
+	NewGRPC(reg)
+	RateLimitThrottled.WithLabelValues("http", "default").Inc()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	names := make(map[string]bool)
+	for _, f := range families {
+		names[f.GetName()] = true
+	}
+	assert.True(t, names["go_goroutines"])
+	assert.True(t, names["rate_limit_throttled_total"])
+
+	t.Run("Independent registries", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			other := NewRegistry()
+			NewHTTP(other)
+			NewGRPC(other)
+		})
+	})
+}
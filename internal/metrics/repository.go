@@ -0,0 +1,13 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	UpsertDiscarded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "max_values_upsert_discarded_total",
+			Help: "Number of max value writes discarded by the conflict policy.",
+		},
+		[]string{"policy"},
+	)
+)
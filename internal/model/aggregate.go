@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// AggregateRecord — агрегаты значений одной записи, кроме max,
+// который хранится отдельно в max_values.
+type AggregateRecord struct {
+	UUID      string
+	Tag       string
+	Timestamp time.Time
+	// Values — значение по имени агрегата.
+	Values map[string]int64
+}
@@ -0,0 +1,33 @@
+package model
+
+type ConsumerGroupStatus struct {
+	Group      string         `json:"group"`
+	State      string         `json:"state"`
+	Protocol   string         `json:"protocol"`
+	Members    []GroupMember  `json:"members"`
+	Partitions []PartitionLag `json:"partitions"`
+	TotalLag   int64          `json:"total_lag"`
+}
+
+type GroupMember struct {
+	MemberID    string            `json:"member_id"`
+	InstanceID  string            `json:"instance_id,omitempty"`
+	ClientID    string            `json:"client_id"`
+	ClientHost  string            `json:"client_host"`
+	Assignments []TopicAssignment `json:"assignments"`
+}
+
+type TopicAssignment struct {
+	Topic      string  `json:"topic"`
+	Partitions []int32 `json:"partitions"`
+}
+
+type PartitionLag struct {
+	Topic           string `json:"topic"`
+	Partition       int32  `json:"partition"`
+	CommittedOffset int64  `json:"committed_offset"`
+	HighWatermark   int64  `json:"high_watermark"`
+	Lag             int64  `json:"lag"`
+	MemberID        string `json:"member_id,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
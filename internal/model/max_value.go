@@ -6,6 +6,9 @@ type MaxValueRecord struct {
 	UUID      string
 	Timestamp time.Time
 	MaxValue  int64
+	// Source заполняется для записей из Kafka и попадает в заголовки результата.
+	// По нему повторная доставка не применяется дважды; replay его не передаёт.
+	Source *RecordSource
 }
 
 type MaxValue struct {
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Record — текущее (или историческое, для as_of) состояние агрегата по uuid.
+type Record struct {
+	UUID      string    `json:"uuid"`
+	Version   int64     `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+	MaxValue  int64     `json:"max_value"`
+}
+
+// RecordCursor — позиция в выдаче ListRecords; записи упорядочены по (ts, uuid).
+type RecordCursor struct {
+	Timestamp time.Time
+	UUID      string
+}
+
+// RecordQuery — страница записей с ts в [From, To] после курсора After.
+type RecordQuery struct {
+	From  time.Time
+	To    time.Time
+	After *RecordCursor
+	Limit int
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+type ReplayState string
+
+const (
+	ReplayRunning   ReplayState = "running"
+	ReplayCompleted ReplayState = "completed"
+	ReplayFailed    ReplayState = "failed"
+	ReplayCancelled ReplayState = "cancelled"
+)
+
+// ReplayRequest задаёт входной топик и начальную позицию повторной обработки:
+// либо момент времени для всех партиций, либо офсеты конкретных партиций.
+// Пустой Topic означает единственный явно привязанный топик.
+type ReplayRequest struct {
+	Topic         string          `json:"topic,omitempty"`
+	FromTimestamp *time.Time      `json:"from_timestamp,omitempty"`
+	Offsets       map[int32]int64 `json:"offsets,omitempty"`
+}
+
+type ReplayPartitionProgress struct {
+	Partition     int32 `json:"partition"`
+	StartOffset   int64 `json:"start_offset"`
+	CurrentOffset int64 `json:"current_offset"`
+	EndOffset     int64 `json:"end_offset"`
+}
+
+type ReplayProgress struct {
+	Partitions []ReplayPartitionProgress `json:"partitions"`
+	Processed  int64                     `json:"processed"`
+	Failed     int64                     `json:"failed"`
+}
+
+type ReplayJob struct {
+	ID         string         `json:"id"`
+	Topic      string         `json:"topic"`
+	State      ReplayState    `json:"state"`
+	Request    ReplayRequest  `json:"request"`
+	Progress   ReplayProgress `json:"progress"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
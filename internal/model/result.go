@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// RecordSource — позиция исходной записи во входном топике.
+type RecordSource struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+// MaxValueResult — агрегат, публикуемый в топик результатов после сохранения.
+type MaxValueResult struct {
+	UUID      string    `json:"uuid"`
+	Version   int64     `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+	MaxValue  int64     `json:"max_value"`
+}
+
+// OutboxMessage — ещё не опубликованный результат из таблицы outbox.
+type OutboxMessage struct {
+	ID     int64
+	Topic  string
+	Result MaxValueResult
+	Source *RecordSource
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type MaxValueRevision struct {
+	UUID       string    `json:"uuid"`
+	Version    int64     `json:"version"`
+	Timestamp  time.Time `json:"timestamp"`
+	MaxValue   int64     `json:"max_value"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
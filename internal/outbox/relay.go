@@ -0,0 +1,74 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+// Store выдаёт неопубликованные сообщения и удаляет их,
+// только если publish завершился без ошибки.
+type Store interface {
+	PublishOutbox(ctx context.Context, limit int, publish func(context.Context, []model.OutboxMessage) error) (int, error)
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, msgs []model.OutboxMessage) error
+}
+
+// Relay переносит сообщения из outbox в Kafka. Сообщение удаляется после
+// подтверждения брокером, поэтому при сбое возможен повтор, но не потеря.
+type Relay struct {
+	store     Store
+	publisher Publisher
+	interval  time.Duration
+	batchSize int
+	log       *slog.Logger
+}
+
+func NewRelay(store Store, publisher Publisher, cfg config.OutboxConfig, log *slog.Logger) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		interval:  cfg.PollInterval,
+		batchSize: max(cfg.BatchSize, 1),
+		log:       log.With("component", "outbox_relay"),
+	}
+}
+
+func (r *Relay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("outbox relay stopped")
+			return
+
+		case <-ticker.C:
+			r.drain(ctx)
+		}
+	}
+}
+
+// drain публикует пачки, пока outbox не опустеет.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := r.store.PublishOutbox(ctx, r.batchSize, r.publisher.Publish)
+		if err != nil {
+			metrics.OutboxPublishErrors.Inc()
+			r.log.Error("failed to publish outbox", slog.Any("error", err))
+			return
+		}
+
+		metrics.OutboxPublished.Add(float64(n))
+		if n < r.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+// memoryStore удаляет сообщения только после успешной публикации, как и postgres.
+type memoryStore struct {
+	msgs []model.OutboxMessage
+}
+
+func (s *memoryStore) PublishOutbox(
+	ctx context.Context,
+	limit int,
+	publish func(context.Context, []model.OutboxMessage) error,
+) (int, error) {
+	batch := s.msgs[:min(limit, len(s.msgs))]
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	if err := publish(ctx, batch); err != nil {
+		return 0, err
+	}
+	s.msgs = s.msgs[len(batch):]
+	return len(batch), nil
+}
+
+type publisherFunc func(ctx context.Context, msgs []model.OutboxMessage) error
+
+func (f publisherFunc) Publish(ctx context.Context, msgs []model.OutboxMessage) error {
+	return f(ctx, msgs)
+}
+
+func testMessages(n int) []model.OutboxMessage {
+	msgs := make([]model.OutboxMessage, n)
+	for i := range msgs {
+		msgs[i] = model.OutboxMessage{ID: int64(i + 1), Topic: "results"}
+	}
+	return msgs
+}
+
+func TestRelay_Drain(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := config.OutboxConfig{PollInterval: time.Second, BatchSize: 2}
+
+	t.Run("Publishes all batches in order", func(t *testing.T) {
+		store := &memoryStore{msgs: testMessages(5)}
+		var published []int64
+
+		relay := NewRelay(store, publisherFunc(func(_ context.Context, msgs []model.OutboxMessage) error {
+			for _, m := range msgs {
+				published = append(published, m.ID)
+			}
+			return nil
+		}), cfg, logger)
+		relay.drain(context.Background())
+
+		assert.Equal(t, []int64{1, 2, 3, 4, 5}, published)
+		assert.Empty(t, store.msgs)
+	})
+
+	t.Run("Keeps messages on publish error", func(t *testing.T) {
+		store := &memoryStore{msgs: testMessages(3)}
+
+		relay := NewRelay(store, publisherFunc(func(context.Context, []model.OutboxMessage) error {
+			return errors.New("broker unavailable")
+		}), cfg, logger)
+		relay.drain(context.Background())
+
+		assert.Len(t, store.msgs, 3)
+	})
+}
@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"golang.org/x/time/rate"
+)
+
+// Class — группа запросов со своей корзиной.
+type Class string
+
+const (
+	ClassDefault Class = "default"
+	// ClassPeriod — запросы за период, самые дорогие для Postgres.
+	ClassPeriod Class = "period"
+)
+
+// Limiter хранит корзины по клиентам отдельно для каждого класса.
+type Limiter struct {
+	classes map[Class]*buckets
+}
+
+// New возвращает nil, если ограничение выключено.
+func New(cfg config.RateLimitConfig) *Limiter {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Limiter{classes: map[Class]*buckets{
+		ClassDefault: newBuckets(rate.Limit(cfg.RPS), cfg.Burst, cfg.IdleTTL, cfg.MaxClients),
+		ClassPeriod:  newBuckets(rate.Limit(cfg.PeriodRPS), cfg.PeriodBurst, cfg.IdleTTL, cfg.MaxClients),
+	}}
+}
+
+// Allow забирает токен из корзины клиента. Если токена нет, возвращает,
+// через сколько он появится.
+func (l *Limiter) Allow(class Class, client string) (bool, time.Duration) {
+	b, ok := l.classes[class]
+	if !ok {
+		b = l.classes[ClassDefault]
+	}
+	return b.allow(client, time.Now())
+}
+
+// ClientKey — ключ корзины: аутентифицированный клиент из контекста или IP из
+// remoteAddr, чтобы клиенты за одним NAT с разными ключами не мешали друг другу.
+func ClientKey(ctx context.Context, remoteAddr string) string {
+	if p, ok := auth.FromContext(ctx); ok {
+		return p.Method + ":" + p.Subject
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return "ip:" + host
+}
+
+type buckets struct {
+	limit      rate.Limit
+	burst      int
+	idleTTL    time.Duration
+	maxClients int
+
+	mu      sync.Mutex
+	clients map[string]*bucket
+	sweptAt time.Time
+	// overflow — общая корзина новых клиентов, когда clients заполнен:
+	// поток запросов с разных адресов не раздувает память и всё равно ограничен.
+	overflow *bucket
+}
+
+type bucket struct {
+	limiter *rate.Limiter
+	seenAt  time.Time
+}
+
+func newBuckets(limit rate.Limit, burst int, idleTTL time.Duration, maxClients int) *buckets {
+	return &buckets{
+		limit:      limit,
+		burst:      burst,
+		idleTTL:    idleTTL,
+		maxClients: maxClients,
+		clients:    make(map[string]*bucket),
+		sweptAt:    time.Now(),
+		overflow:   &bucket{limiter: rate.NewLimiter(limit, burst)},
+	}
+}
+
+func (b *buckets) allow(client string, now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweep(now)
+
+	c, ok := b.clients[client]
+	if !ok && b.full() {
+		b.evictIdle(now)
+	}
+	switch {
+	case ok:
+	case b.full():
+		c = b.overflow
+	default:
+		c = &bucket{limiter: rate.NewLimiter(b.limit, b.burst)}
+		b.clients[client] = c
+	}
+	c.seenAt = now
+
+	r := c.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		// Burst 0 — запросы этого класса запрещены.
+		return false, 0
+	}
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep удаляет корзины клиентов, которые давно не приходили. Корзина
+// успевает заполниться за idleTTL, так что клиент ничего не теряет.
+func (b *buckets) sweep(now time.Time) {
+	if b.idleTTL <= 0 || now.Sub(b.sweptAt) < b.idleTTL {
+		return
+	}
+	b.evictIdle(now)
+}
+
+func (b *buckets) full() bool {
+	return b.maxClients > 0 && len(b.clients) >= b.maxClients
+}
+
+// evictIdle удаляет корзины, не использованные дольше idleTTL.
+func (b *buckets) evictIdle(now time.Time) {
+	b.sweptAt = now
+	for client, c := range b.clients {
+		if now.Sub(c.seenAt) > b.idleTTL {
+			delete(b.clients, client)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	l := New(config.RateLimitConfig{
+		Enabled:     true,
+		RPS:         1,
+		Burst:       3,
+		PeriodRPS:   0.5,
+		PeriodBurst: 1,
+		IdleTTL:     time.Minute,
+	})
+
+	t.Run("Burst then throttled", func(t *testing.T) {
+		for range 3 {
+			ok, _ := l.Allow(ClassDefault, "ip:10.0.0.1")
+			assert.True(t, ok)
+		}
+		ok, retryAfter := l.Allow(ClassDefault, "ip:10.0.0.1")
+		assert.False(t, ok)
+		assert.InDelta(t, time.Second, retryAfter, float64(50*time.Millisecond))
+	})
+
+	t.Run("Clients are independent", func(t *testing.T) {
+		ok, _ := l.Allow(ClassDefault, "ip:10.0.0.2")
+		assert.True(t, ok)
+	})
+
+	t.Run("Period queries have their own bucket", func(t *testing.T) {
+		ok, _ := l.Allow(ClassPeriod, "ip:10.0.0.1")
+		assert.True(t, ok, "default bucket exhaustion does not affect period bucket")
+
+		ok, retryAfter := l.Allow(ClassPeriod, "ip:10.0.0.1")
+		assert.False(t, ok)
+		assert.InDelta(t, 2*time.Second, retryAfter, float64(50*time.Millisecond))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		assert.Nil(t, New(config.RateLimitConfig{}))
+	})
+}
+
+func TestBuckets_Sweep(t *testing.T) {
+	b := newBuckets(1, 1, time.Minute, 0)
+	start := time.Now()
+
+	b.allow("a", start)
+	b.allow("b", start.Add(50*time.Second))
+	assert.Len(t, b.clients, 2)
+
+	b.allow("b", start.Add(90*time.Second))
+	assert.Len(t, b.clients, 1, "idle client is dropped")
+	assert.Contains(t, b.clients, "b")
+}
+
+func TestBuckets_MaxClients(t *testing.T) {
+	b := newBuckets(1, 1, time.Minute, 2)
+	start := time.Now()
+
+	ok, _ := b.allow("a", start)
+	assert.True(t, ok)
+	ok, _ = b.allow("b", start)
+	assert.True(t, ok)
+
+	t.Run("New clients share the overflow bucket", func(t *testing.T) {
+		ok, _ := b.allow("c", start)
+		assert.True(t, ok)
+		ok, _ = b.allow("d", start)
+		assert.False(t, ok, "overflow bucket is exhausted by c")
+		assert.Len(t, b.clients, 2)
+	})
+
+	t.Run("Known clients keep their buckets", func(t *testing.T) {
+		ok, _ := b.allow("a", start.Add(time.Second))
+		assert.True(t, ok)
+	})
+
+	t.Run("Idle clients make room", func(t *testing.T) {
+		b.allow("e", start.Add(30*time.Second))
+		assert.NotContains(t, b.clients, "e", "nobody is idle yet")
+
+		b.allow("a", start.Add(50*time.Second))
+		ok, _ = b.allow("e", start.Add(70*time.Second))
+		assert.True(t, ok)
+		assert.Contains(t, b.clients, "e")
+		assert.NotContains(t, b.clients, "b")
+	})
+}
+
+func TestClientKey(t *testing.T) {
+	assert.Equal(t, "ip:192.0.2.1", ClientKey(context.Background(), "192.0.2.1:51234"))
+	assert.Equal(t, "ip:2001:db8::1", ClientKey(context.Background(), "[2001:db8::1]:443"))
+	assert.Equal(t, "ip:192.0.2.1", ClientKey(context.Background(), "192.0.2.1"))
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "grafana", Method: auth.MethodAPIKey})
+	assert.Equal(t, "apikey:grafana", ClientKey(ctx, "192.0.2.1:51234"))
+}
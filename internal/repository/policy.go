@@ -0,0 +1,29 @@
+package repository
+
+import "fmt"
+
+// ConflictPolicy определяет, как SaveMax разрешает конфликт по uuid,
+// когда запись для него уже существует.
+type ConflictPolicy string
+
+const (
+	// PolicyLatestTimestamp — побеждает запись с более поздним ts,
+	// опоздавшие и повторно доставленные старые сообщения отбрасываются.
+	PolicyLatestTimestamp ConflictPolicy = "latest_ts"
+	// PolicyGreatestMax — сохраняется наибольший max_value.
+	PolicyGreatestMax ConflictPolicy = "greatest_max"
+	// PolicyRejectDuplicates — первая запись для uuid окончательна.
+	PolicyRejectDuplicates ConflictPolicy = "reject_duplicates"
+	// PolicyVersionHistory — каждая запись сохраняется в истории версий,
+	// текущее значение определяется по ts.
+	PolicyVersionHistory ConflictPolicy = "version_history"
+)
+
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(s); p {
+	case PolicyLatestTimestamp, PolicyGreatestMax, PolicyRejectDuplicates, PolicyVersionHistory:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy %q", s)
+	}
+}
@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+func (d *Database) SaveAggregates(ctx context.Context, rec *model.AggregateRecord) error {
+	const q = `
+		INSERT INTO aggregates (uuid, aggregator, tag, ts, value)
+		SELECT $1, a.aggregator, $2, $3, a.value
+		FROM unnest($4::text[], $5::bigint[]) AS a(aggregator, value)
+		ON CONFLICT (uuid, aggregator, tag) DO UPDATE SET
+			ts = EXCLUDED.ts,
+			value = EXCLUDED.value
+		WHERE aggregates.ts <= EXCLUDED.ts
+	`
+
+	names := make([]string, 0, len(rec.Values))
+	values := make([]int64, 0, len(rec.Values))
+	for name, v := range rec.Values {
+		names = append(names, name)
+		values = append(values, v)
+	}
+
+	if _, err := d.db.Exec(ctx, q, rec.UUID, rec.Tag, rec.Timestamp, names, values); err != nil {
+		d.log.ErrorContext(ctx, "SaveAggregates failed", slog.String("uuid", rec.UUID), slog.Any("error", err))
+		return classify(err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		invalid bool
+	}{
+		{"String too long", &pgconn.PgError{Code: "22001"}, true},
+		{"Numeric out of range", fmt.Errorf("query: %w", &pgconn.PgError{Code: "22003"}), true},
+		{"Not null violation", &pgconn.PgError{Code: "23502"}, true},
+		{"Connection failure", &pgconn.PgError{Code: "08006"}, false},
+		{"Serialization failure", &pgconn.PgError{Code: "40001"}, false},
+		{"Context canceled", context.Canceled, false},
+		{"Network error", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classify(tt.err)
+			assert.Equal(t, tt.invalid, errors.Is(err, repository.ErrInvalidRecord))
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+// outboxLockID — ключ advisory-блокировки: публикует только один экземпляр
+// сервиса за раз, иначе результаты одного uuid могли бы уйти не по порядку.
+const outboxLockID = 0x6f7574626f78
+
+// PublishOutbox передаёт publish до limit самых старых сообщений outbox и удаляет
+// их после успешной публикации. Возвращает число опубликованных сообщений;
+// 0 без ошибки — outbox пуст или его обрабатывает другой экземпляр.
+func (d *Database) PublishOutbox(
+	ctx context.Context,
+	limit int,
+	publish func(context.Context, []model.OutboxMessage) error,
+) (int, error) {
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin outbox transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockID).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("lock outbox: %w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	const q = `
+		SELECT id, topic, uuid, version, ts, max_value, source_topic, source_partition, source_offset
+		FROM outbox
+		ORDER BY id
+		LIMIT $1
+	`
+
+	rows, err := tx.Query(ctx, q, limit)
+	if err != nil {
+		d.log.Error("PublishOutbox failed", slog.Any("error", err))
+		return 0, err
+	}
+	defer rows.Close()
+
+	var (
+		msgs []model.OutboxMessage
+		ids  []int64
+	)
+	for rows.Next() {
+		var (
+			msg       model.OutboxMessage
+			topic     *string
+			partition *int32
+			offset    *int64
+		)
+		err := rows.Scan(
+			&msg.ID, &msg.Topic,
+			&msg.Result.UUID, &msg.Result.Version, &msg.Result.Timestamp, &msg.Result.MaxValue,
+			&topic, &partition, &offset,
+		)
+		if err != nil {
+			return 0, err
+		}
+		if topic != nil && partition != nil && offset != nil {
+			msg.Source = &model.RecordSource{Topic: *topic, Partition: *partition, Offset: *offset}
+		}
+
+		msgs = append(msgs, msg)
+		ids = append(ids, msg.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+
+	if err := publish(ctx, msgs); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM outbox WHERE id = ANY($1)`, ids); err != nil {
+		return 0, fmt.Errorf("delete published outbox messages: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit outbox transaction: %w", err)
+	}
+
+	return len(msgs), nil
+}
@@ -5,21 +5,35 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
 	"github.com/Pavel26ru/aggregator-service/internal/model"
 	"github.com/Pavel26ru/aggregator-service/internal/repository"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Database struct {
-	db  *pgxpool.Pool
-	log *slog.Logger
+	db     *pgxpool.Pool
+	log    *slog.Logger
+	policy repository.ConflictPolicy
+
+	// outboxTopic — топик результатов; пустое значение отключает запись в outbox.
+	outboxTopic string
+	saveMax     string
 }
 
-func New(ctx context.Context, cfg config.PostgresConfig, log *slog.Logger) (*Database, error) {
+func New(ctx context.Context, cfg config.PostgresConfig, outboxTopic string, tp trace.TracerProvider, log *slog.Logger) (*Database, error) {
+	policy, err := repository.ParseConflictPolicy(cfg.ConflictPolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	dsn := fmt.Sprintf(
 		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		cfg.User,
@@ -30,7 +44,13 @@ func New(ctx context.Context, cfg config.PostgresConfig, log *slog.Logger) (*Dat
 		cfg.SSLMode,
 	)
 
-	pool, err := pgxpool.New(ctx, dsn)
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database config: %w", err)
+	}
+	poolCfg.ConnConfig.Tracer = newQueryTracer(tp)
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
@@ -40,7 +60,23 @@ func New(ctx context.Context, cfg config.PostgresConfig, log *slog.Logger) (*Dat
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &Database{db: pool, log: log}, nil
+	return &Database{
+		db:          pool,
+		log:         log,
+		policy:      policy,
+		outboxTopic: outboxTopic,
+		saveMax:     saveMaxQuery(policy, outboxTopic != ""),
+	}, nil
+}
+
+// classify помечает ErrInvalidRecord ошибки данных (класс SQLSTATE 22) и нарушения
+// ограничений (класс 23): они повторятся при любой следующей попытке.
+func classify(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")) {
+		return fmt.Errorf("%w: %w", repository.ErrInvalidRecord, err)
+	}
+	return err
 }
 
 func (d *Database) Close() {
@@ -48,20 +84,36 @@ func (d *Database) Close() {
 	d.db.Close()
 }
 
-func (d *Database) SaveMax(ctx context.Context, rec *model.MaxValueRecord) error {
-	const q = `
-		INSERT INTO max_values (uuid, ts, max_value)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (uuid) DO UPDATE SET
-			ts = EXCLUDED.ts,
-			max_value = EXCLUDED.max_value;
-	`
+func (d *Database) SaveMax(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
+	var (
+		topic     *string
+		partition *int32
+		offset    *int64
+	)
+	if rec.Source != nil {
+		topic, partition, offset = &rec.Source.Topic, &rec.Source.Partition, &rec.Source.Offset
+	}
+	args := []any{rec.UUID, rec.Timestamp, rec.MaxValue, topic, partition, offset}
+	if d.outboxTopic != "" {
+		args = append(args, d.outboxTopic)
+	}
 
-	if _, err := d.db.Exec(ctx, q, rec.UUID, rec.Timestamp, rec.MaxValue); err != nil {
-		d.log.Error("SaveMax failed", slog.Any("error", err))
-		return err
+	res := model.MaxValueResult{UUID: rec.UUID}
+	err := d.db.QueryRow(ctx, d.saveMax, args...).Scan(&res.Version, &res.Timestamp, &res.MaxValue)
+	if errors.Is(err, pgx.ErrNoRows) {
+		metrics.UpsertDiscarded.WithLabelValues(string(d.policy)).Inc()
+		d.log.DebugContext(ctx, "SaveMax discarded by conflict policy",
+			slog.String("uuid", rec.UUID),
+			slog.String("policy", string(d.policy)),
+		)
+		return nil, nil
+	}
+	if err != nil {
+		d.log.ErrorContext(ctx, "SaveMax failed", slog.String("uuid", rec.UUID), slog.Any("error", err))
+		return nil, classify(err)
 	}
-	return nil
+
+	return &res, nil
 }
 
 func (d *Database) GetMaxByID(ctx context.Context, uuid string) (*model.MaxValue, error) {
@@ -116,3 +168,60 @@ func (r *Database) GetMaxByPeriod(ctx context.Context, from, to time.Time) ([]mo
 
 	return records, nil
 }
+
+func (d *Database) GetMaxAsOf(ctx context.Context, uuid string, asOf time.Time) (*model.MaxValue, error) {
+	const q = `
+		SELECT max_value
+		FROM max_values_history
+		WHERE uuid = $1 AND ts <= $2
+		ORDER BY ts DESC, version DESC
+		LIMIT 1
+	`
+
+	var rec model.MaxValue
+	err := d.db.QueryRow(ctx, q, uuid, asOf).Scan(&rec.Value)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		d.log.Error("GetMaxAsOf failed", slog.Any("error", err))
+		return nil, err
+	}
+
+	return &rec, nil
+}
+
+func (d *Database) ListRevisions(ctx context.Context, uuid string) ([]model.MaxValueRevision, error) {
+	const q = `
+		SELECT uuid, version, ts, max_value, recorded_at
+		FROM max_values_history
+		WHERE uuid = $1
+		ORDER BY version ASC
+	`
+
+	rows, err := d.db.Query(ctx, q, uuid)
+	if err != nil {
+		d.log.Error("ListRevisions failed", slog.Any("error", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []model.MaxValueRevision
+	for rows.Next() {
+		var rev model.MaxValueRevision
+		if err := rows.Scan(&rev.UUID, &rev.Version, &rev.Timestamp, &rev.MaxValue, &rev.RecordedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		d.log.Error("ListRevisions row iteration failed", slog.Any("error", err))
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return revisions, nil
+}
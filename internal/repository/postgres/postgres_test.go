@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+)
+
+// newTestDatabase подключается к TEST_POSTGRES_DSN и накатывает миграции
+// в отдельную схему, которая удаляется после теста. Без TEST_POSTGRES_DSN
+// тест пропускается.
+func newTestDatabase(t *testing.T, policy repository.ConflictPolicy, outboxTopic string) *Database {
+	t.Helper()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	ctx := context.Background()
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	admin, err := pgxpool.New(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(admin.Close)
+	_, err = admin.Exec(ctx, "CREATE SCHEMA "+schema)
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE") })
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	require.NoError(t, err)
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	migrations, err := filepath.Glob("../../../migrations/*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	sort.Strings(migrations)
+	for _, path := range migrations {
+		sql, err := os.ReadFile(path)
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, string(sql))
+		require.NoError(t, err, path)
+	}
+
+	return &Database{
+		db:          pool,
+		log:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+		policy:      policy,
+		outboxTopic: outboxTopic,
+		saveMax:     saveMaxQuery(policy, outboxTopic != ""),
+	}
+}
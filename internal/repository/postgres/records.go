@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+func (d *Database) GetRecord(ctx context.Context, uuid string) (*model.Record, error) {
+	const q = `
+		SELECT uuid, version, ts, max_value
+		FROM max_values
+		WHERE uuid = $1
+	`
+
+	var rec model.Record
+	err := d.db.QueryRow(ctx, q, uuid).Scan(&rec.UUID, &rec.Version, &rec.Timestamp, &rec.MaxValue)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		d.log.ErrorContext(ctx, "GetRecord failed", slog.Any("error", err))
+		return nil, err
+	}
+
+	return &rec, nil
+}
+
+func (d *Database) GetRecordAsOf(ctx context.Context, uuid string, asOf time.Time) (*model.Record, error) {
+	const q = `
+		SELECT uuid, version, ts, max_value
+		FROM max_values_history
+		WHERE uuid = $1 AND ts <= $2
+		ORDER BY ts DESC, version DESC
+		LIMIT 1
+	`
+
+	var rec model.Record
+	err := d.db.QueryRow(ctx, q, uuid, asOf).Scan(&rec.UUID, &rec.Version, &rec.Timestamp, &rec.MaxValue)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		d.log.ErrorContext(ctx, "GetRecordAsOf failed", slog.Any("error", err))
+		return nil, err
+	}
+
+	return &rec, nil
+}
+
+func (d *Database) ListRecords(ctx context.Context, rq model.RecordQuery) ([]model.Record, error) {
+	// Курсор (ts, uuid) вместо OFFSET: страница читается по индексу ts
+	// независимо от того, насколько далеко клиент пролистал.
+	const q = `
+		SELECT uuid, version, ts, max_value
+		FROM max_values
+		WHERE ts >= $1 AND ts <= $2
+		  AND ($3::timestamp IS NULL OR (ts, uuid) > ($3, $4))
+		ORDER BY ts ASC, uuid ASC
+		LIMIT $5
+	`
+
+	var (
+		afterTS   *time.Time
+		afterUUID string
+	)
+	if rq.After != nil {
+		afterTS, afterUUID = &rq.After.Timestamp, rq.After.UUID
+	}
+
+	rows, err := d.db.Query(ctx, q, rq.From, rq.To, afterTS, afterUUID, rq.Limit)
+	if err != nil {
+		d.log.ErrorContext(ctx, "ListRecords failed", slog.Any("error", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]model.Record, 0, rq.Limit)
+	for rows.Next() {
+		var rec model.Record
+		if err := rows.Scan(&rec.UUID, &rec.Version, &rec.Timestamp, &rec.MaxValue); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		d.log.ErrorContext(ctx, "ListRecords row iteration failed", slog.Any("error", err))
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (d *Database) ListSeries(ctx context.Context, uuid string, from, to time.Time) ([]model.MaxValueRevision, error) {
+	const q = `
+		SELECT uuid, version, ts, max_value, recorded_at
+		FROM max_values_history
+		WHERE uuid = $1 AND ts >= $2 AND ts <= $3
+		ORDER BY ts ASC, version ASC
+	`
+
+	rows, err := d.db.Query(ctx, q, uuid, from, to)
+	if err != nil {
+		d.log.ErrorContext(ctx, "ListSeries failed", slog.Any("error", err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := make([]model.MaxValueRevision, 0)
+	for rows.Next() {
+		var rev model.MaxValueRevision
+		if err := rows.Scan(&rev.UUID, &rev.Version, &rev.Timestamp, &rev.MaxValue, &rev.RecordedAt); err != nil {
+			return nil, err
+		}
+		series = append(series, rev)
+	}
+	if err := rows.Err(); err != nil {
+		d.log.ErrorContext(ctx, "ListSeries row iteration failed", slog.Any("error", err))
+		return nil, err
+	}
+
+	return series, nil
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/service/mocks"
+	"github.com/Pavel26ru/aggregator-service/internal/transport/rest"
+)
+
+func TestQueryTracer_RequestParent(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := newQueryTracer(tp)
+
+	// Репозиторий выполняет запрос так же, как pgx: через QueryTracer соединения.
+	repo := &mocks.MockMaxValueRepository{
+		GetMaxByIDFunc: func(ctx context.Context, _ string) (*model.MaxValue, error) {
+			ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT max_value FROM max_values WHERE uuid = $1"})
+			tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+			return &model.MaxValue{Value: 3}, nil
+		},
+	}
+	reg := prometheus.NewRegistry()
+	router := rest.New(service.New(logger, repo), nil, logger, metrics.NewHTTP(reg), reg, tp, nil, true, nil, nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/max?uuid=a1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	query, request := spans[0], spans[1]
+	assert.Equal(t, "SELECT", query.Name)
+	assert.Equal(t, "GET /max", request.Name)
+	assert.Equal(t, request.SpanContext.SpanID(), query.Parent.SpanID())
+	assert.Equal(t, request.SpanContext.TraceID(), query.SpanContext.TraceID())
+}
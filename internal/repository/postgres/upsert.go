@@ -0,0 +1,98 @@
+package postgres
+
+import "github.com/Pavel26ru/aggregator-service/internal/repository"
+
+// saveMaxUpserts — upsert в max_values для каждой политики. Обёртки withHistory
+// и withOutbox добавляют применённую запись в max_values_history под новой версией
+// и возвращают состояние записи после upsert. Запрос не возвращает строк,
+// если запись отброшена политикой.
+//
+// Запись из Kafka, уже применённая раньше (тот же топик, партиция и офсет, те же
+// uuid и ts), не применяется повторно: upsert пропускается через CTE applied,
+// а запрос возвращает версию из истории. Так повторная доставка после прерванной
+// транзакции или ребаланса не создаёт лишних версий и строк истории. Replay
+// передаёт запись без источника, поэтому она пересчитывается.
+var saveMaxUpserts = map[repository.ConflictPolicy]string{
+	repository.PolicyLatestTimestamp: `
+			INSERT INTO max_values (uuid, ts, max_value)
+			SELECT $1::varchar, $2::timestamp, $3::bigint WHERE NOT EXISTS (SELECT 1 FROM applied)
+			ON CONFLICT (uuid) DO UPDATE SET
+				ts = EXCLUDED.ts,
+				max_value = EXCLUDED.max_value,
+				version = max_values.version + 1
+			WHERE max_values.ts <= EXCLUDED.ts`,
+	repository.PolicyGreatestMax: `
+			INSERT INTO max_values (uuid, ts, max_value)
+			SELECT $1::varchar, $2::timestamp, $3::bigint WHERE NOT EXISTS (SELECT 1 FROM applied)
+			ON CONFLICT (uuid) DO UPDATE SET
+				ts = EXCLUDED.ts,
+				max_value = EXCLUDED.max_value,
+				version = max_values.version + 1
+			WHERE max_values.max_value < EXCLUDED.max_value`,
+	repository.PolicyRejectDuplicates: `
+			INSERT INTO max_values (uuid, ts, max_value)
+			SELECT $1::varchar, $2::timestamp, $3::bigint WHERE NOT EXISTS (SELECT 1 FROM applied)
+			ON CONFLICT (uuid) DO NOTHING`,
+	// Версия увеличивается при каждой записи, а текущее значение
+	// заменяется только более новым по ts.
+	repository.PolicyVersionHistory: `
+			INSERT INTO max_values (uuid, ts, max_value)
+			SELECT $1::varchar, $2::timestamp, $3::bigint WHERE NOT EXISTS (SELECT 1 FROM applied)
+			ON CONFLICT (uuid) DO UPDATE SET
+				ts = CASE WHEN max_values.ts <= EXCLUDED.ts THEN EXCLUDED.ts ELSE max_values.ts END,
+				max_value = CASE WHEN max_values.ts <= EXCLUDED.ts THEN EXCLUDED.max_value ELSE max_values.max_value END,
+				version = max_values.version + 1`,
+}
+
+func saveMaxQuery(policy repository.ConflictPolicy, outbox bool) string {
+	if outbox {
+		return withOutbox(saveMaxUpserts[policy])
+	}
+	return withHistory(saveMaxUpserts[policy])
+}
+
+func withHistory(upsert string) string {
+	return `
+		WITH applied AS (` + appliedQuery + `
+		), upsert AS (` + upsert + `
+			RETURNING version, ts, max_value
+		), history AS (` + historyInsert + `
+		)
+		SELECT version, ts, max_value FROM upsert
+		UNION ALL
+		SELECT version, ts, max_value FROM applied;
+	`
+}
+
+// withOutbox дополнительно ставит в outbox текущее состояние записи после
+// upsert. Всё выполняется одним запросом, поэтому сохранение и постановка
+// в очередь публикации не могут разойтись. Повторно доставленная запись
+// в outbox не попадает: её результат уже поставлен в очередь.
+func withOutbox(upsert string) string {
+	return `
+		WITH applied AS (` + appliedQuery + `
+		), upsert AS (` + upsert + `
+			RETURNING version, ts, max_value
+		), history AS (` + historyInsert + `
+		), queued AS (
+			INSERT INTO outbox (topic, uuid, version, ts, max_value, source_topic, source_partition, source_offset)
+			SELECT $7::text, $1, version, ts, max_value, $4, $5, $6 FROM upsert
+		)
+		SELECT version, ts, max_value FROM upsert
+		UNION ALL
+		SELECT version, ts, max_value FROM applied;
+	`
+}
+
+// appliedQuery находит версию, под которой запись из источника $4, $5, $6 уже
+// сохранена. У записей без источника (REST, gRPC, replay) параметры NULL, и совпадений нет.
+// uuid и ts сверяются, потому что после пересоздания топика офсеты начинаются заново
+// и под уже применённым офсетом приходит другая запись.
+const appliedQuery = `
+			SELECT version, ts, max_value FROM max_values_history
+			WHERE source_topic = $4::text AND source_partition = $5::integer AND source_offset = $6::bigint
+				AND uuid = $1::varchar AND ts = $2::timestamp`
+
+const historyInsert = `
+			INSERT INTO max_values_history (uuid, version, ts, max_value, source_topic, source_partition, source_offset)
+			SELECT $1, version, $2, $3, $4, $5, $6 FROM upsert`
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+)
+
+func TestDatabase_SaveMax(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Каждый случай сначала сохраняет запись с ts = base и max = 10,
+	// затем применяет вторую запись для того же uuid.
+	type want struct {
+		applied  bool
+		version  int64
+		maxValue int64
+		history  int
+	}
+	cases := []struct {
+		name   string
+		policy repository.ConflictPolicy
+		ts     time.Time
+		max    int64
+		want   want
+	}{
+		{"Latest ts: older ts discarded", repository.PolicyLatestTimestamp, base.Add(-time.Minute), 20, want{false, 1, 10, 1}},
+		{"Latest ts: equal ts applied", repository.PolicyLatestTimestamp, base, 20, want{true, 2, 20, 2}},
+		{"Latest ts: newer ts applied", repository.PolicyLatestTimestamp, base.Add(time.Minute), 5, want{true, 2, 5, 2}},
+		{"Greatest max: smaller max discarded", repository.PolicyGreatestMax, base.Add(time.Minute), 5, want{false, 1, 10, 1}},
+		{"Greatest max: equal max discarded", repository.PolicyGreatestMax, base.Add(time.Minute), 10, want{false, 1, 10, 1}},
+		{"Greatest max: greater max from older ts applied", repository.PolicyGreatestMax, base.Add(-time.Minute), 20, want{true, 2, 20, 2}},
+		{"Reject duplicates: newer ts discarded", repository.PolicyRejectDuplicates, base.Add(time.Minute), 20, want{false, 1, 10, 1}},
+		{"Version history: older ts keeps value", repository.PolicyVersionHistory, base.Add(-time.Minute), 20, want{true, 2, 10, 2}},
+		{"Version history: newer ts replaces value", repository.PolicyVersionHistory, base.Add(time.Minute), 20, want{true, 2, 20, 2}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			d := newTestDatabase(t, tc.policy, "")
+
+			first, err := d.SaveMax(ctx, &model.MaxValueRecord{UUID: "u1", Timestamp: base, MaxValue: 10})
+			require.NoError(t, err)
+			require.NotNil(t, first)
+			assert.Equal(t, int64(1), first.Version)
+
+			res, err := d.SaveMax(ctx, &model.MaxValueRecord{UUID: "u1", Timestamp: tc.ts, MaxValue: tc.max})
+			require.NoError(t, err)
+			if tc.want.applied {
+				require.NotNil(t, res)
+				assert.Equal(t, tc.want.version, res.Version)
+			} else {
+				assert.Nil(t, res)
+			}
+
+			var version, maxValue int64
+			require.NoError(t, d.db.QueryRow(ctx, `SELECT version, max_value FROM max_values WHERE uuid = 'u1'`).Scan(&version, &maxValue))
+			assert.Equal(t, tc.want.version, version)
+			assert.Equal(t, tc.want.maxValue, maxValue)
+
+			var history int
+			require.NoError(t, d.db.QueryRow(ctx, `SELECT count(*) FROM max_values_history WHERE uuid = 'u1'`).Scan(&history))
+			assert.Equal(t, tc.want.history, history)
+		})
+	}
+}
+
+func TestDatabase_SaveMax_Redelivered(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	source := &model.RecordSource{Topic: "records", Partition: 3, Offset: 42}
+
+	for _, policy := range []repository.ConflictPolicy{
+		repository.PolicyLatestTimestamp,
+		repository.PolicyGreatestMax,
+		repository.PolicyVersionHistory,
+	} {
+		for _, outboxTopic := range []string{"", "results"} {
+			t.Run(string(policy)+"/outbox="+outboxTopic, func(t *testing.T) {
+				ctx := context.Background()
+				d := newTestDatabase(t, policy, outboxTopic)
+
+				_, err := d.SaveMax(ctx, &model.MaxValueRecord{UUID: "u1", Timestamp: base, MaxValue: 10})
+				require.NoError(t, err)
+
+				rec := &model.MaxValueRecord{UUID: "u1", Timestamp: base.Add(time.Minute), MaxValue: 20, Source: source}
+				first, err := d.SaveMax(ctx, rec)
+				require.NoError(t, err)
+				require.NotNil(t, first)
+				assert.Equal(t, int64(2), first.Version)
+
+				// Повторная доставка той же записи возвращает ту же версию и ничего не меняет.
+				again, err := d.SaveMax(ctx, rec)
+				require.NoError(t, err)
+				require.NotNil(t, again)
+				assert.Equal(t, first.Version, again.Version)
+				assert.Equal(t, first.MaxValue, again.MaxValue)
+
+				var version int64
+				require.NoError(t, d.db.QueryRow(ctx, `SELECT version FROM max_values WHERE uuid = 'u1'`).Scan(&version))
+				assert.Equal(t, int64(2), version)
+
+				var history int
+				require.NoError(t, d.db.QueryRow(ctx, `SELECT count(*) FROM max_values_history WHERE uuid = 'u1'`).Scan(&history))
+				assert.Equal(t, 2, history)
+
+				if outboxTopic != "" {
+					var queued int
+					require.NoError(t, d.db.QueryRow(ctx, `SELECT count(*) FROM outbox WHERE uuid = 'u1'`).Scan(&queued))
+					assert.Equal(t, 2, queued)
+				}
+
+				// Запись без источника (REST, gRPC) применяется каждый раз.
+				for i := range 2 {
+					ts := base.Add(time.Duration(i+2) * time.Minute)
+					_, err = d.SaveMax(ctx, &model.MaxValueRecord{UUID: "u1", Timestamp: ts, MaxValue: int64(30 + i)})
+					require.NoError(t, err)
+				}
+				require.NoError(t, d.db.QueryRow(ctx, `SELECT count(*) FROM max_values_history WHERE uuid = 'u1'`).Scan(&history))
+				assert.Equal(t, 4, history)
+			})
+		}
+	}
+}
+
+func TestDatabase_SaveMax_Replayed(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	source := &model.RecordSource{Topic: "records", Partition: 0, Offset: 7}
+
+	t.Run("Replay recomputes an applied offset", func(t *testing.T) {
+		ctx := context.Background()
+		d := newTestDatabase(t, repository.PolicyLatestTimestamp, "")
+
+		_, err := d.SaveMax(ctx, &model.MaxValueRecord{UUID: "u1", Timestamp: base, MaxValue: 10, Source: source})
+		require.NoError(t, err)
+
+		// После исправления агрегации replay той же записи даёт другое значение;
+		// Replayer передаёт её без источника.
+		res, err := d.SaveMax(ctx, &model.MaxValueRecord{UUID: "u1", Timestamp: base, MaxValue: 12})
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		assert.Equal(t, int64(2), res.Version)
+		assert.Equal(t, int64(12), res.MaxValue)
+
+		var maxValue int64
+		require.NoError(t, d.db.QueryRow(ctx, `SELECT max_value FROM max_values WHERE uuid = 'u1'`).Scan(&maxValue))
+		assert.Equal(t, int64(12), maxValue)
+	})
+
+	t.Run("Recreated topic reuses offsets", func(t *testing.T) {
+		ctx := context.Background()
+		d := newTestDatabase(t, repository.PolicyLatestTimestamp, "")
+
+		_, err := d.SaveMax(ctx, &model.MaxValueRecord{UUID: "u1", Timestamp: base, MaxValue: 10, Source: source})
+		require.NoError(t, err)
+
+		// Топик пересоздан: под тем же офсетом пришла другая запись.
+		res, err := d.SaveMax(ctx, &model.MaxValueRecord{UUID: "u2", Timestamp: base.Add(time.Hour), MaxValue: 30, Source: source})
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		assert.Equal(t, int64(1), res.Version)
+
+		var maxValue int64
+		require.NoError(t, d.db.QueryRow(ctx, `SELECT max_value FROM max_values WHERE uuid = 'u2'`).Scan(&maxValue))
+		assert.Equal(t, int64(30), maxValue)
+	})
+}
@@ -10,8 +10,26 @@ import (
 
 var ErrNotFound = errors.New("not found")
 
+// ErrInvalidRecord — хранилище отвергло запись (значение вне диапазона, слишком
+// длинный uuid, нарушение ограничения): повторная попытка не поможет.
+var ErrInvalidRecord = errors.New("invalid record")
+
 type MaxValueRepository interface {
-	SaveMax(ctx context.Context, rec *model.MaxValueRecord) error
+	// SaveMax возвращает состояние записи после сохранения
+	// или nil, если запись отброшена политикой конфликтов.
+	SaveMax(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error)
+	// SaveAggregates сохраняет агрегаты записи; по каждому агрегату и тегу
+	// остаётся значение с наибольшим ts.
+	SaveAggregates(ctx context.Context, rec *model.AggregateRecord) error
 	GetMaxByID(ctx context.Context, uuid string) (*model.MaxValue, error)
 	GetMaxByPeriod(ctx context.Context, from, to time.Time) ([]model.MaxValue, error)
+	GetMaxAsOf(ctx context.Context, uuid string, asOf time.Time) (*model.MaxValue, error)
+	ListRevisions(ctx context.Context, uuid string) ([]model.MaxValueRevision, error)
+
+	GetRecord(ctx context.Context, uuid string) (*model.Record, error)
+	GetRecordAsOf(ctx context.Context, uuid string, asOf time.Time) (*model.Record, error)
+	// ListRecords возвращает не больше q.Limit записей в порядке (ts, uuid).
+	ListRecords(ctx context.Context, q model.RecordQuery) ([]model.Record, error)
+	// ListSeries возвращает версии записи с ts в [from, to] в порядке ts.
+	ListSeries(ctx context.Context, uuid string, from, to time.Time) ([]model.MaxValueRevision, error)
 }
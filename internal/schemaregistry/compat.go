@@ -0,0 +1,183 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// checkBackward проверяет совместимость BACKWARD в терминах Confluent Schema
+// Registry: потребитель со схемой next должен прочитать данные, записанные
+// по предыдущей версии prev того же subject.
+func checkBackward(prev, next Schema) error {
+	if prev.Type != next.Type {
+		return incompatible("schema type changed from %s to %s", prev.Type, next.Type)
+	}
+
+	switch next.Type {
+	case TypeAvro:
+		return avroBackward(prev.Schema, next.Schema)
+	case TypeProtobuf:
+		return protobufBackward(prev.Schema, next.Schema)
+	case TypeJSON:
+		return jsonBackward(prev.Schema, next.Schema)
+	default:
+		return fmt.Errorf("unsupported schema type %q", next.Type)
+	}
+}
+
+func avroBackward(prevText, nextText string) error {
+	writer, err := avro.Parse(prevText)
+	if err != nil {
+		return fmt.Errorf("parse previous avro schema: %w", err)
+	}
+	reader, err := avro.Parse(nextText)
+	if err != nil {
+		return fmt.Errorf("parse avro schema: %w", err)
+	}
+	if err := avro.NewSchemaCompatibility().Compatible(reader, writer); err != nil {
+		return incompatible("%v", err)
+	}
+	return nil
+}
+
+func protobufBackward(prevText, nextText string) error {
+	prev, err := parseProtobuf(prevText)
+	if err != nil {
+		return fmt.Errorf("previous schema: %w", err)
+	}
+	next, err := parseProtobuf(nextText)
+	if err != nil {
+		return err
+	}
+	return protoMessagesBackward(prev.Messages(), protoMessagesByName(next.Messages(), nil))
+}
+
+func protoMessagesByName(msgs protoreflect.MessageDescriptors, out map[protoreflect.FullName]protoreflect.MessageDescriptor) map[protoreflect.FullName]protoreflect.MessageDescriptor {
+	if out == nil {
+		out = make(map[protoreflect.FullName]protoreflect.MessageDescriptor)
+	}
+	for i := 0; i < msgs.Len(); i++ {
+		md := msgs.Get(i)
+		out[md.FullName()] = md
+		protoMessagesByName(md.Messages(), out)
+	}
+	return out
+}
+
+// protoMessagesBackward сверяет каждое сообщение прежней схемы с одноимённым
+// сообщением новой. Удалять поля можно, менять тип и кратность поля
+// с тем же номером, а также удалять сообщения — нельзя.
+func protoMessagesBackward(prev protoreflect.MessageDescriptors, next map[protoreflect.FullName]protoreflect.MessageDescriptor) error {
+	for i := 0; i < prev.Len(); i++ {
+		pm := prev.Get(i)
+		nm, ok := next[pm.FullName()]
+		if !ok {
+			return incompatible("message %s was removed", pm.FullName())
+		}
+
+		for j := 0; j < pm.Fields().Len(); j++ {
+			pf := pm.Fields().Get(j)
+			nf := nm.Fields().ByNumber(pf.Number())
+			if nf == nil {
+				continue
+			}
+			if pf.Cardinality() != nf.Cardinality() || !protoWireCompatible(pf, nf) {
+				return incompatible("%s: field %d changed from %s %s to %s %s",
+					pm.FullName(), pf.Number(), pf.Cardinality(), protoTypeName(pf), nf.Cardinality(), protoTypeName(nf))
+			}
+		}
+
+		if err := protoMessagesBackward(pm.Messages(), next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoWireCompatible сообщает, читается ли значение поля prev как поле next:
+// типы должны совпадать по кодированию в wire format.
+func protoWireCompatible(prev, next protoreflect.FieldDescriptor) bool {
+	if prev.Kind() == protoreflect.MessageKind || next.Kind() == protoreflect.MessageKind {
+		return prev.Kind() == next.Kind() && prev.Message().FullName() == next.Message().FullName()
+	}
+	return protoWireGroup(prev.Kind()) == protoWireGroup(next.Kind())
+}
+
+func protoWireGroup(k protoreflect.Kind) string {
+	switch k {
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Uint32Kind,
+		protoreflect.Uint64Kind, protoreflect.BoolKind, protoreflect.EnumKind:
+		return "varint"
+	case protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		return "zigzag"
+	case protoreflect.Fixed32Kind, protoreflect.Sfixed32Kind:
+		return "fixed32"
+	case protoreflect.Fixed64Kind, protoreflect.Sfixed64Kind:
+		return "fixed64"
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return "bytes"
+	default:
+		return k.String()
+	}
+}
+
+func protoTypeName(fd protoreflect.FieldDescriptor) string {
+	if fd.Kind() == protoreflect.MessageKind {
+		return string(fd.Message().FullName())
+	}
+	return fd.Kind().String()
+}
+
+type jsonSchema struct {
+	Properties map[string]struct {
+		Type any `json:"type"`
+	} `json:"properties"`
+	Required []string `json:"required"`
+}
+
+// jsonBackward запрещает менять тип существующих свойств и делать
+// обязательными свойства, которых в прежних данных могло не быть.
+func jsonBackward(prevText, nextText string) error {
+	var prev, next jsonSchema
+	if err := json.Unmarshal([]byte(prevText), &prev); err != nil {
+		return fmt.Errorf("parse previous json schema: %w", err)
+	}
+	if err := json.Unmarshal([]byte(nextText), &next); err != nil {
+		return fmt.Errorf("parse json schema: %w", err)
+	}
+
+	for name, np := range next.Properties {
+		pp, ok := prev.Properties[name]
+		if ok && !reflect.DeepEqual(pp.Type, np.Type) {
+			return incompatible("property %s changed type from %v to %v", name, pp.Type, np.Type)
+		}
+	}
+	for _, name := range next.Required {
+		if !slices.Contains(prev.Required, name) {
+			return incompatible("property %s became required", name)
+		}
+	}
+	return nil
+}
+
+// previousVersion ищет последнюю зарегистрированную версию subject до version;
+// удалённые версии пропускаются. Если предыдущих версий нет, ok == false.
+func previousVersion(ctx context.Context, r Resolver, subject string, version int) (Schema, bool, error) {
+	for v := version - 1; v >= 1; v-- {
+		s, err := r.SchemaByVersion(ctx, subject, v)
+		if err == nil {
+			return s, true, nil
+		}
+		if !errors.Is(err, ErrSchemaNotFound) {
+			return Schema{}, false, err
+		}
+	}
+	return Schema{}, false, nil
+}
@@ -0,0 +1,274 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// Новое поле с default: прежние данные читаются.
+	avroSchemaWithDefault = `{
+		"type": "record",
+		"name": "ValueRecord",
+		"fields": [
+			{"name": "uuid", "type": "string"},
+			{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "value", "type": {"type": "array", "items": "long"}},
+			{"name": "source", "type": "string", "default": ""}
+		]
+	}`
+	// Новое поле без default: в прежних данных его нет.
+	avroSchemaWithRequired = `{
+		"type": "record",
+		"name": "ValueRecord",
+		"fields": [
+			{"name": "uuid", "type": "string"},
+			{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "value", "type": {"type": "array", "items": "long"}},
+			{"name": "source", "type": "string"}
+		]
+	}`
+	// Тип поля source изменён: string из прежних данных не читается как long.
+	avroSchemaSourceLong = `{
+		"type": "record",
+		"name": "ValueRecord",
+		"fields": [
+			{"name": "uuid", "type": "string"},
+			{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "value", "type": {"type": "array", "items": "long"}},
+			{"name": "source", "type": "long", "default": 0}
+		]
+	}`
+)
+
+func TestCheckBackward(t *testing.T) {
+	cases := []struct {
+		name       string
+		prev, next Schema
+		compatible bool
+	}{
+		{
+			name:       "Avro field with default added",
+			prev:       Schema{Type: TypeAvro, Schema: avroSchema},
+			next:       Schema{Type: TypeAvro, Schema: avroSchemaWithDefault},
+			compatible: true,
+		},
+		{
+			name: "Avro field without default added",
+			prev: Schema{Type: TypeAvro, Schema: avroSchema},
+			next: Schema{Type: TypeAvro, Schema: avroSchemaWithRequired},
+		},
+		{
+			name: "Protobuf field added and removed",
+			prev: Schema{Type: TypeProtobuf, Schema: protoSchema},
+			next: Schema{Type: TypeProtobuf, Schema: `
+				syntax = "proto3";
+				import "google/protobuf/timestamp.proto";
+				message ValueRecord {
+					string uuid = 1;
+					google.protobuf.Timestamp timestamp = 2;
+					repeated sfixed64 value = 5;
+					string source = 4;
+				}`},
+			compatible: true,
+		},
+		{
+			name:       "Protobuf int32 widened to int64",
+			prev:       Schema{Type: TypeProtobuf, Schema: `syntax = "proto3"; message M { int32 a = 1; }`},
+			next:       Schema{Type: TypeProtobuf, Schema: `syntax = "proto3"; message M { int64 a = 1; }`},
+			compatible: true,
+		},
+		{
+			name: "Protobuf field type changed",
+			prev: Schema{Type: TypeProtobuf, Schema: protoSchema},
+			next: Schema{Type: TypeProtobuf, Schema: `
+				syntax = "proto3";
+				message ValueRecord {
+					string uuid = 1;
+					int64 timestamp = 2;
+					repeated int64 value = 3;
+				}`},
+		},
+		{
+			name: "Protobuf repeated became singular",
+			prev: Schema{Type: TypeProtobuf, Schema: `syntax = "proto3"; message M { repeated int64 a = 1; }`},
+			next: Schema{Type: TypeProtobuf, Schema: `syntax = "proto3"; message M { int64 a = 1; }`},
+		},
+		{
+			name: "Protobuf message removed",
+			prev: Schema{Type: TypeProtobuf, Schema: `syntax = "proto3"; message M { int64 a = 1; } message N { int64 b = 1; }`},
+			next: Schema{Type: TypeProtobuf, Schema: `syntax = "proto3"; message M { int64 a = 1; }`},
+		},
+		{
+			name:       "JSON optional property added",
+			prev:       Schema{Type: TypeJSON, Schema: `{"properties": {"uuid": {"type": "string"}}, "required": ["uuid"]}`},
+			next:       Schema{Type: TypeJSON, Schema: `{"properties": {"uuid": {"type": "string"}, "source": {"type": "string"}}, "required": ["uuid"]}`},
+			compatible: true,
+		},
+		{
+			name: "JSON property became required",
+			prev: Schema{Type: TypeJSON, Schema: `{"properties": {"uuid": {"type": "string"}, "source": {"type": "string"}}}`},
+			next: Schema{Type: TypeJSON, Schema: `{"properties": {"uuid": {"type": "string"}, "source": {"type": "string"}}, "required": ["source"]}`},
+		},
+		{
+			name: "JSON property type changed",
+			prev: Schema{Type: TypeJSON, Schema: `{"properties": {"uuid": {"type": "string"}}}`},
+			next: Schema{Type: TypeJSON, Schema: `{"properties": {"uuid": {"type": "integer"}}}`},
+		},
+		{
+			name: "Schema type changed",
+			prev: Schema{Type: TypeAvro, Schema: avroSchema},
+			next: Schema{Type: TypeProtobuf, Schema: protoSchema},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkBackward(tc.prev, tc.next)
+			if tc.compatible {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrIncompatible)
+			}
+		})
+	}
+}
+
+func TestDeserializer_SubjectCompatibility(t *testing.T) {
+	ctx := context.Background()
+	const subject = "values-value"
+
+	d := newTestDeserializer(t, []Schema{
+		{ID: 10, Subject: subject, Version: 1, Type: TypeAvro, Schema: avroSchema},
+		{ID: 11, Subject: subject, Version: 2, Type: TypeAvro, Schema: avroSchemaWithDefault},
+		// Версия 3 удалена из реестра, версия 4 сверяется со второй.
+		{ID: 13, Subject: subject, Version: 4, Type: TypeAvro, Schema: avroSchemaSourceLong},
+	})
+
+	payload, err := avro.Marshal(avro.MustParse(avroSchemaWithDefault), map[string]any{
+		"uuid":      "abc",
+		"timestamp": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		"value":     []int64{1},
+		"source":    "s",
+	})
+	require.NoError(t, err)
+
+	t.Run("Compatible version", func(t *testing.T) {
+		rec, err := d.Decode(ctx, frame(11, payload))
+		require.NoError(t, err)
+		assert.Equal(t, "abc", rec.UUID)
+	})
+
+	t.Run("Incompatible with previous version", func(t *testing.T) {
+		_, err := d.Decode(ctx, frame(13, payload))
+		assert.ErrorIs(t, err, ErrIncompatible)
+	})
+}
+
+func TestHTTPResolver_SchemaByVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subjects/values-value/versions/2" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"subject": "values-value",
+			"id":      11,
+			"version": 2,
+			"schema":  avroSchema,
+		})
+	}))
+	defer srv.Close()
+
+	resolver := NewHTTPResolver(srv.URL, "", "", time.Second)
+
+	s, err := resolver.SchemaByVersion(context.Background(), "values-value", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 11, s.ID)
+	assert.Equal(t, 2, s.Version)
+	assert.Equal(t, TypeAvro, s.Type)
+
+	_, err = resolver.SchemaByVersion(context.Background(), "values-value", 1)
+	assert.ErrorIs(t, err, ErrSchemaNotFound)
+}
+
+func TestHTTPResolver_SubjectCompatibility(t *testing.T) {
+	const subject = "values-value"
+	var failing atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		// Как и в Confluent, /schemas/ids/{id} не содержит subject и версии.
+		switch r.URL.Path {
+		case "/schemas/ids/11", "/subjects/" + subject + "/versions/1":
+			_ = json.NewEncoder(w).Encode(map[string]any{"schema": avroSchemaWithDefault})
+		case "/schemas/ids/11/versions":
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"subject": subject, "version": 1}})
+		case "/schemas/ids/13":
+			_ = json.NewEncoder(w).Encode(map[string]any{"schema": avroSchemaSourceLong})
+		case "/schemas/ids/13/versions":
+			_ = json.NewEncoder(w).Encode([]map[string]any{{"subject": subject, "version": 2}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	resolver := NewHTTPResolver(srv.URL, "", "", time.Second)
+	d := NewDeserializer(resolver, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	ctx := context.Background()
+
+	payload, err := avro.Marshal(avro.MustParse(avroSchemaWithDefault), map[string]any{
+		"uuid":      "abc",
+		"timestamp": time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		"value":     []int64{1},
+		"source":    "s",
+	})
+	require.NoError(t, err)
+
+	t.Run("Subject and version resolved by id", func(t *testing.T) {
+		s, err := resolver.SchemaByID(ctx, 13)
+		require.NoError(t, err)
+		assert.Equal(t, subject, s.Subject)
+		assert.Equal(t, 2, s.Version)
+	})
+
+	t.Run("Compatible version", func(t *testing.T) {
+		rec, err := d.Decode(ctx, frame(11, payload))
+		require.NoError(t, err)
+		assert.Equal(t, "abc", rec.UUID)
+	})
+
+	t.Run("Incompatible with previous version", func(t *testing.T) {
+		_, err := d.Decode(ctx, frame(13, payload))
+		assert.ErrorIs(t, err, ErrIncompatible)
+	})
+
+	t.Run("Registry unavailable", func(t *testing.T) {
+		failing.Store(true)
+		defer failing.Store(false)
+
+		_, err := d.Decode(ctx, frame(12, payload))
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.NotErrorIs(t, err, ErrSchemaNotFound)
+	})
+
+	t.Run("Unknown schema", func(t *testing.T) {
+		_, err := d.Decode(ctx, frame(12, payload))
+		assert.ErrorIs(t, err, ErrSchemaNotFound)
+		assert.NotErrorIs(t, err, ErrUnavailable)
+	})
+}
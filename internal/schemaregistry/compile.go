@@ -0,0 +1,246 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+// decoder разбирает полезную нагрузку сообщения после идентификатора схемы.
+type decoder func(payload []byte) (model.ValueRecord, error)
+
+// compile проверяет, что схема отображается на ValueRecord
+// (поля uuid, timestamp и value совместимых типов), и строит для неё decoder.
+func compile(s Schema) (decoder, error) {
+	switch s.Type {
+	case TypeAvro:
+		return compileAvro(s.Schema)
+	case TypeProtobuf:
+		return compileProtobuf(s.Schema)
+	case TypeJSON:
+		return compileJSON(s.Schema)
+	default:
+		return nil, fmt.Errorf("unsupported schema type %q", s.Type)
+	}
+}
+
+func incompatible(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrIncompatible, fmt.Sprintf(format, args...))
+}
+
+func compileAvro(text string) (decoder, error) {
+	schema, err := avro.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse avro schema: %w", err)
+	}
+
+	rec, ok := schema.(*avro.RecordSchema)
+	if !ok {
+		return nil, incompatible("avro schema must be a record, got %s", schema.Type())
+	}
+
+	fields := make(map[string]avro.Schema, len(rec.Fields()))
+	for _, f := range rec.Fields() {
+		fields[f.Name()] = f.Type()
+	}
+
+	if t, ok := fields["uuid"]; !ok || t.Type() != avro.String {
+		return nil, incompatible("field uuid must be a string")
+	}
+	if t, ok := fields["timestamp"]; !ok || t.Type() != avro.Long {
+		return nil, incompatible("field timestamp must be a long")
+	}
+	arr, ok := fields["value"].(*avro.ArraySchema)
+	if !ok || (arr.Items().Type() != avro.Long && arr.Items().Type() != avro.Int) {
+		return nil, incompatible("field value must be an array of long or int")
+	}
+
+	return func(payload []byte) (model.ValueRecord, error) {
+		var m map[string]any
+		if err := avro.Unmarshal(schema, payload, &m); err != nil {
+			return model.ValueRecord{}, err
+		}
+
+		rec := model.ValueRecord{}
+		rec.UUID, _ = m["uuid"].(string)
+		switch ts := m["timestamp"].(type) {
+		case time.Time:
+			rec.Timestamp = ts.UTC()
+		case int64:
+			rec.Timestamp = time.UnixMilli(ts).UTC()
+		}
+		items, _ := m["value"].([]any)
+		for _, it := range items {
+			switch v := it.(type) {
+			case int64:
+				rec.Value = append(rec.Value, v)
+			case int32:
+				rec.Value = append(rec.Value, int64(v))
+			case int:
+				rec.Value = append(rec.Value, int64(v))
+			}
+		}
+		return rec, nil
+	}, nil
+}
+
+const protoSchemaFile = "schema.proto"
+
+func parseProtobuf(text string) (protoreflect.FileDescriptor, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{protoSchemaFile: text}),
+		}),
+	}
+
+	files, err := compiler.Compile(context.Background(), protoSchemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("compile protobuf schema: %w", err)
+	}
+	return files[0], nil
+}
+
+func compileProtobuf(text string) (decoder, error) {
+	fd, err := parseProtobuf(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if fd.Messages().Len() == 0 {
+		return nil, incompatible("protobuf schema has no messages")
+	}
+	// Большинство продюсеров пишут первое сообщение файла,
+	// поэтому его совместимость проверяется сразу.
+	if _, err := protoFieldsOf(fd.Messages().Get(0)); err != nil {
+		return nil, err
+	}
+
+	return func(payload []byte) (model.ValueRecord, error) {
+		indexes, rest, err := readMessageIndexes(payload)
+		if err != nil {
+			return model.ValueRecord{}, err
+		}
+
+		md, err := messageByIndexes(fd, indexes)
+		if err != nil {
+			return model.ValueRecord{}, err
+		}
+		fields, err := protoFieldsOf(md)
+		if err != nil {
+			return model.ValueRecord{}, err
+		}
+
+		msg := dynamicpb.NewMessage(md)
+		if err := proto.Unmarshal(rest, msg); err != nil {
+			return model.ValueRecord{}, err
+		}
+		return fields.toRecord(msg), nil
+	}, nil
+}
+
+type protoFields struct {
+	uuid, timestamp, value protoreflect.FieldDescriptor
+}
+
+func protoFieldsOf(md protoreflect.MessageDescriptor) (protoFields, error) {
+	f := protoFields{
+		uuid:      md.Fields().ByName("uuid"),
+		timestamp: md.Fields().ByName("timestamp"),
+		value:     md.Fields().ByName("value"),
+	}
+
+	if f.uuid == nil || f.uuid.Kind() != protoreflect.StringKind || f.uuid.IsList() {
+		return f, incompatible("%s: field uuid must be a string", md.FullName())
+	}
+	if f.timestamp == nil || f.timestamp.IsList() || !isProtoTimestamp(f.timestamp) {
+		return f, incompatible("%s: field timestamp must be google.protobuf.Timestamp or int64 millis", md.FullName())
+	}
+	if f.value == nil || !f.value.IsList() || !isProtoInteger(f.value.Kind()) {
+		return f, incompatible("%s: field value must be a repeated integer", md.FullName())
+	}
+
+	return f, nil
+}
+
+func isProtoTimestamp(fd protoreflect.FieldDescriptor) bool {
+	if fd.Kind() == protoreflect.MessageKind {
+		return fd.Message().FullName() == "google.protobuf.Timestamp"
+	}
+	return fd.Kind() == protoreflect.Int64Kind
+}
+
+func isProtoInteger(k protoreflect.Kind) bool {
+	switch k {
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return true
+	}
+	return false
+}
+
+func (f protoFields) toRecord(msg protoreflect.Message) model.ValueRecord {
+	rec := model.ValueRecord{UUID: msg.Get(f.uuid).String()}
+
+	if msg.Has(f.timestamp) {
+		if f.timestamp.Kind() == protoreflect.MessageKind {
+			ts := msg.Get(f.timestamp).Message()
+			fields := ts.Descriptor().Fields()
+			rec.Timestamp = time.Unix(
+				ts.Get(fields.ByName("seconds")).Int(),
+				ts.Get(fields.ByName("nanos")).Int(),
+			).UTC()
+		} else {
+			rec.Timestamp = time.UnixMilli(msg.Get(f.timestamp).Int()).UTC()
+		}
+	}
+
+	list := msg.Get(f.value).List()
+	rec.Value = make([]int64, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		rec.Value = append(rec.Value, list.Get(i).Int())
+	}
+	return rec
+}
+
+func messageByIndexes(fd protoreflect.FileDescriptor, indexes []int) (protoreflect.MessageDescriptor, error) {
+	msgs := fd.Messages()
+	var md protoreflect.MessageDescriptor
+	for _, i := range indexes {
+		if i < 0 || i >= msgs.Len() {
+			return nil, fmt.Errorf("message index %d out of range", i)
+		}
+		md = msgs.Get(i)
+		msgs = md.Messages()
+	}
+	return md, nil
+}
+
+func compileJSON(text string) (decoder, error) {
+	var schema struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(text), &schema); err != nil {
+		return nil, fmt.Errorf("parse json schema: %w", err)
+	}
+
+	for _, name := range []string{"uuid", "timestamp", "value"} {
+		if _, ok := schema.Properties[name]; !ok {
+			return nil, incompatible("json schema has no property %s", name)
+		}
+	}
+
+	return func(payload []byte) (model.ValueRecord, error) {
+		var rec model.ValueRecord
+		err := json.Unmarshal(payload, &rec)
+		return rec, err
+	}, nil
+}
@@ -0,0 +1,108 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+type compiled struct {
+	decode decoder
+	err    error
+}
+
+// Deserializer декодирует сообщения в формате Confluent в ValueRecord.
+// Схема запрашивается у Resolver при первом появлении идентификатора
+// и проверяется на совместимость с ValueRecord и с предыдущей версией
+// того же subject (BACKWARD); результат кэшируется.
+type Deserializer struct {
+	resolver Resolver
+	log      *slog.Logger
+
+	mu      sync.RWMutex
+	schemas map[int]compiled
+}
+
+func NewDeserializer(resolver Resolver, log *slog.Logger) *Deserializer {
+	return &Deserializer{
+		resolver: resolver,
+		log:      log.With(slog.String("component", "schema_registry")),
+		schemas:  make(map[int]compiled),
+	}
+}
+
+func (d *Deserializer) Decode(ctx context.Context, data []byte) (model.ValueRecord, error) {
+	id, payload, err := parseFrame(data)
+	if err != nil {
+		return model.ValueRecord{}, err
+	}
+
+	c, err := d.schema(ctx, id)
+	if err != nil {
+		return model.ValueRecord{}, err
+	}
+	if c.err != nil {
+		return model.ValueRecord{}, fmt.Errorf("schema %d: %w", id, c.err)
+	}
+
+	return c.decode(payload)
+}
+
+func (d *Deserializer) schema(ctx context.Context, id int) (compiled, error) {
+	d.mu.RLock()
+	c, ok := d.schemas[id]
+	d.mu.RUnlock()
+	if ok {
+		return c, nil
+	}
+
+	s, err := d.resolver.SchemaByID(ctx, id)
+	if err != nil {
+		// Ошибки получения схемы не кэшируются: реестр может быть временно недоступен.
+		return compiled{}, fmt.Errorf("resolve schema %d: %w", id, unavailable(err))
+	}
+
+	c.decode, c.err = compile(s)
+	if c.err == nil && s.Subject != "" && s.Version > 1 {
+		prev, ok, err := previousVersion(ctx, d.resolver, s.Subject, s.Version)
+		if err != nil {
+			return compiled{}, fmt.Errorf("resolve previous version of schema %d: %w", id, unavailable(err))
+		}
+		if ok {
+			c.err = checkBackward(prev, s)
+		}
+	}
+
+	log := d.log.With(
+		slog.Int("schema_id", id),
+		slog.String("subject", s.Subject),
+		slog.String("type", string(s.Type)),
+	)
+	if c.err != nil {
+		if errors.Is(c.err, ErrIncompatible) {
+			log.Error("incompatible schema detected", slog.Any("error", c.err))
+		} else {
+			log.Error("failed to compile schema", slog.Any("error", c.err))
+		}
+	} else {
+		log.Info("new schema accepted")
+	}
+
+	d.mu.Lock()
+	d.schemas[id] = c
+	d.mu.Unlock()
+
+	return c, nil
+}
+
+// unavailable помечает ErrUnavailable все ошибки реестра, кроме отсутствующей схемы.
+func unavailable(err error) error {
+	if errors.Is(err, ErrSchemaNotFound) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
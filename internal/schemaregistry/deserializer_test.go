@@ -0,0 +1,151 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/Pavel26ru/aggregator-service/gen"
+)
+
+const (
+	avroSchema = `{
+		"type": "record",
+		"name": "ValueRecord",
+		"fields": [
+			{"name": "uuid", "type": "string"},
+			{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "value", "type": {"type": "array", "items": "long"}}
+		]
+	}`
+	incompatibleAvroSchema = `{
+		"type": "record",
+		"name": "ValueRecord",
+		"fields": [{"name": "uuid", "type": "string"}]
+	}`
+	protoSchema = `
+		syntax = "proto3";
+		import "google/protobuf/timestamp.proto";
+		message ValueRecord {
+			string uuid = 1;
+			google.protobuf.Timestamp timestamp = 2;
+			repeated int64 value = 3;
+		}`
+)
+
+func frame(id int, payload []byte) []byte {
+	out := []byte{magicByte, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(out[1:], uint32(id))
+	return append(out, payload...)
+}
+
+func newTestDeserializer(t *testing.T, schemas []Schema) *Deserializer {
+	data, err := json.Marshal(schemas)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "schemas.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	resolver, err := NewFileResolver(path)
+	require.NoError(t, err)
+
+	return NewDeserializer(resolver, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+}
+
+func TestDeserializer_Decode(t *testing.T) {
+	ctx := context.Background()
+	ts := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	d := newTestDeserializer(t, []Schema{
+		{ID: 1, Type: TypeAvro, Schema: avroSchema},
+		{ID: 2, Type: TypeProtobuf, Schema: protoSchema},
+		{ID: 3, Type: TypeAvro, Schema: incompatibleAvroSchema},
+	})
+
+	t.Run("Avro", func(t *testing.T) {
+		schema := avro.MustParse(avroSchema)
+		payload, err := avro.Marshal(schema, map[string]any{
+			"uuid":      "abc",
+			"timestamp": ts,
+			"value":     []int64{1, 5, 3},
+		})
+		require.NoError(t, err)
+
+		rec, err := d.Decode(ctx, frame(1, payload))
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc", rec.UUID)
+		assert.True(t, ts.Equal(rec.Timestamp))
+		assert.Equal(t, []int64{1, 5, 3}, rec.Value)
+	})
+
+	t.Run("Protobuf", func(t *testing.T) {
+		payload, err := proto.Marshal(&pb.ValueRecord{
+			Uuid:      "abc",
+			Timestamp: timestamppb.New(ts),
+			Value:     []int64{7, 2},
+		})
+		require.NoError(t, err)
+
+		// Одиночный 0 — индекс первого сообщения в файле.
+		rec, err := d.Decode(ctx, frame(2, append([]byte{0}, payload...)))
+
+		require.NoError(t, err)
+		assert.Equal(t, "abc", rec.UUID)
+		assert.True(t, ts.Equal(rec.Timestamp))
+		assert.Equal(t, []int64{7, 2}, rec.Value)
+	})
+
+	t.Run("Malformed protobuf frame", func(t *testing.T) {
+		// Количество индексов 2^62 не должно приводить к выделению памяти под них.
+		_, err := d.Decode(ctx, frame(2, binary.AppendVarint(nil, 1<<62)))
+
+		assert.ErrorIs(t, err, errBadFrame)
+	})
+
+	t.Run("Incompatible schema", func(t *testing.T) {
+		_, err := d.Decode(ctx, frame(3, []byte{0}))
+
+		assert.ErrorIs(t, err, ErrIncompatible)
+	})
+
+	t.Run("Unknown schema", func(t *testing.T) {
+		_, err := d.Decode(ctx, frame(42, []byte{0}))
+
+		assert.ErrorIs(t, err, ErrSchemaNotFound)
+	})
+}
+
+func TestHTTPResolver_SchemaByID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schemas/ids/7" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"schema": avroSchema})
+	}))
+	defer srv.Close()
+
+	resolver := NewHTTPResolver(srv.URL, "", "", time.Second)
+
+	s, err := resolver.SchemaByID(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, 7, s.ID)
+	assert.Equal(t, TypeAvro, s.Type)
+
+	_, err = resolver.SchemaByID(context.Background(), 8)
+	assert.ErrorIs(t, err, ErrSchemaNotFound)
+}
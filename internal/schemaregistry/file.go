@@ -0,0 +1,73 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// FileResolver — локальный реестр для работы без Schema Registry.
+// Файл содержит JSON-массив схем в формате Schema.
+type FileResolver struct {
+	schemas  map[int]Schema
+	versions map[subjectVersion]Schema
+}
+
+type subjectVersion struct {
+	subject string
+	version int
+}
+
+func NewFileResolver(path string) (*FileResolver, error) {
+	const op = "schemaregistry.NewFileResolver"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var list []Schema
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	schemas := make(map[int]Schema, len(list))
+	versions := make(map[subjectVersion]Schema, len(list))
+	for _, s := range list {
+		if s.Type == "" {
+			s.Type = TypeAvro
+		}
+		if _, ok := schemas[s.ID]; ok {
+			return nil, fmt.Errorf("%s: duplicate schema id %d", op, s.ID)
+		}
+		schemas[s.ID] = s
+
+		if s.Subject == "" || s.Version == 0 {
+			continue
+		}
+		key := subjectVersion{subject: s.Subject, version: s.Version}
+		if _, ok := versions[key]; ok {
+			return nil, fmt.Errorf("%s: duplicate version %d of subject %s", op, s.Version, s.Subject)
+		}
+		versions[key] = s
+	}
+
+	return &FileResolver{schemas: schemas, versions: versions}, nil
+}
+
+func (r *FileResolver) SchemaByID(_ context.Context, id int) (Schema, error) {
+	s, ok := r.schemas[id]
+	if !ok {
+		return Schema{}, fmt.Errorf("id %d: %w", id, ErrSchemaNotFound)
+	}
+	return s, nil
+}
+
+func (r *FileResolver) SchemaByVersion(_ context.Context, subject string, version int) (Schema, error) {
+	s, ok := r.versions[subjectVersion{subject: subject, version: version}]
+	if !ok {
+		return Schema{}, fmt.Errorf("%s version %d: %w", subject, version, ErrSchemaNotFound)
+	}
+	return s, nil
+}
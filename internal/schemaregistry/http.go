@@ -0,0 +1,104 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPResolver получает схемы из Schema Registry по REST API Confluent.
+type HTTPResolver struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+func NewHTTPResolver(baseURL, username, password string, timeout time.Duration) *HTTPResolver {
+	return &HTTPResolver{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// registeredVersion — элемент ответа /schemas/ids/{id}/versions.
+type registeredVersion struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// SchemaByID дополняет схему subject и версией: /schemas/ids/{id} их не
+// возвращает, а без них Deserializer не сверит схему с предыдущей версией.
+// Если схема зарегистрирована в нескольких subject, берётся первый.
+func (r *HTTPResolver) SchemaByID(ctx context.Context, id int) (Schema, error) {
+	const op = "schemaregistry.HTTPResolver.SchemaByID"
+
+	var s Schema
+	if err := r.get(ctx, fmt.Sprintf("/schemas/ids/%d", id), &s); err != nil {
+		return Schema{}, fmt.Errorf("%s: id %d: %w", op, id, err)
+	}
+	s.ID = id
+	if s.Type == "" {
+		s.Type = TypeAvro
+	}
+
+	var versions []registeredVersion
+	err := r.get(ctx, fmt.Sprintf("/schemas/ids/%d/versions", id), &versions)
+	switch {
+	case errors.Is(err, ErrSchemaNotFound):
+		// Реестр без этого метода: совместимость с прежней версией не проверяется.
+	case err != nil:
+		return Schema{}, fmt.Errorf("%s: versions of id %d: %w", op, id, err)
+	case len(versions) > 0:
+		s.Subject, s.Version = versions[0].Subject, versions[0].Version
+	}
+
+	return s, nil
+}
+
+func (r *HTTPResolver) SchemaByVersion(ctx context.Context, subject string, version int) (Schema, error) {
+	const op = "schemaregistry.HTTPResolver.SchemaByVersion"
+
+	var s Schema
+	if err := r.get(ctx, fmt.Sprintf("/subjects/%s/versions/%d", url.PathEscape(subject), version), &s); err != nil {
+		return Schema{}, fmt.Errorf("%s: %s version %d: %w", op, subject, version, err)
+	}
+	if s.Type == "" {
+		s.Type = TypeAvro
+	}
+
+	return s, nil
+}
+
+func (r *HTTPResolver) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrSchemaNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,39 @@
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+)
+
+type SchemaType string
+
+// Значения совпадают с полем schemaType в API Confluent Schema Registry,
+// отсутствие поля означает AVRO.
+const (
+	TypeAvro     SchemaType = "AVRO"
+	TypeProtobuf SchemaType = "PROTOBUF"
+	TypeJSON     SchemaType = "JSON"
+)
+
+var (
+	ErrSchemaNotFound = errors.New("schema not found")
+	ErrIncompatible   = errors.New("incompatible schema")
+	// ErrUnavailable — схему не удалось получить из реестра (сеть, таймаут, 5xx):
+	// сообщение стоит декодировать повторно, а не отклонять.
+	ErrUnavailable = errors.New("schema registry unavailable")
+)
+
+type Schema struct {
+	ID      int        `json:"id"`
+	Subject string     `json:"subject,omitempty"`
+	Version int        `json:"version,omitempty"`
+	Type    SchemaType `json:"schemaType,omitempty"`
+	Schema  string     `json:"schema"`
+}
+
+// Resolver возвращает схему по её глобальному идентификатору, а также по
+// subject и версии — для проверки совместимости с предыдущей версией.
+type Resolver interface {
+	SchemaByID(ctx context.Context, id int) (Schema, error)
+	SchemaByVersion(ctx context.Context, subject string, version int) (Schema, error)
+}
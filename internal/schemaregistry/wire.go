@@ -0,0 +1,57 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Формат Confluent: магический байт 0, 4 байта идентификатора схемы (big-endian),
+// далее полезная нагрузка. Для protobuf перед сообщением идут индексы сообщения в файле.
+const (
+	magicByte   = 0x00
+	frameHeader = 5
+)
+
+var errBadFrame = errors.New("malformed confluent frame")
+
+// IsFramed сообщает, похожи ли данные на сообщение в формате Confluent.
+func IsFramed(data []byte) bool {
+	return len(data) >= frameHeader && data[0] == magicByte
+}
+
+func parseFrame(data []byte) (int, []byte, error) {
+	if !IsFramed(data) {
+		return 0, nil, errBadFrame
+	}
+	return int(binary.BigEndian.Uint32(data[1:frameHeader])), data[frameHeader:], nil
+}
+
+// readMessageIndexes читает массив индексов protobuf-сообщения,
+// закодированный zigzag varint; одиночный 0 означает [0].
+func readMessageIndexes(data []byte) ([]int, []byte, error) {
+	n, read := binary.Varint(data)
+	if read <= 0 || n < 0 {
+		return nil, nil, errBadFrame
+	}
+	data = data[read:]
+	// Каждый индекс занимает хотя бы байт: большее число — битый кадр,
+	// а не повод выделять под него память.
+	if n > int64(len(data)) {
+		return nil, nil, errBadFrame
+	}
+
+	if n == 0 {
+		return []int{0}, data, nil
+	}
+
+	indexes := make([]int, 0, n)
+	for i := int64(0); i < n; i++ {
+		idx, read := binary.Varint(data)
+		if read <= 0 {
+			return nil, nil, errBadFrame
+		}
+		indexes = append(indexes, int(idx))
+		data = data[read:]
+	}
+	return indexes, data, nil
+}
@@ -0,0 +1,68 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMessageIndexes(t *testing.T) {
+	varints := func(values ...int64) []byte {
+		var out []byte
+		for _, v := range values {
+			out = binary.AppendVarint(out, v)
+		}
+		return out
+	}
+
+	t.Run("Single zero", func(t *testing.T) {
+		indexes, rest, err := readMessageIndexes([]byte{0, 0xAA})
+		require.NoError(t, err)
+		assert.Equal(t, []int{0}, indexes)
+		assert.Equal(t, []byte{0xAA}, rest)
+	})
+
+	t.Run("Nested message", func(t *testing.T) {
+		indexes, rest, err := readMessageIndexes(append(varints(2, 1, 3), 0xAA))
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 3}, indexes)
+		assert.Equal(t, []byte{0xAA}, rest)
+	})
+
+	malformed := map[string][]byte{
+		"Empty":                  nil,
+		"Negative count":         varints(-1),
+		"Huge count":             varints(1 << 62),
+		"Count beyond data":      varints(3, 1),
+		"Truncated index varint": append(varints(1), 0x80),
+		"Truncated count varint": {0x80, 0x80},
+	}
+	for name, data := range malformed {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := readMessageIndexes(data)
+			assert.ErrorIs(t, err, errBadFrame)
+		})
+	}
+}
+
+func FuzzReadMessageIndexes(f *testing.F) {
+	f.Add([]byte{0})
+	f.Add([]byte{4, 2, 6})
+	f.Add(binary.AppendVarint(nil, 1<<62))
+	f.Add([]byte{0x80, 0x80, 0x80})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		indexes, rest, err := readMessageIndexes(data)
+		if err != nil {
+			return
+		}
+		if len(indexes) == 0 {
+			t.Fatalf("no indexes without error for %x", data)
+		}
+		if len(rest) > len(data) {
+			t.Fatalf("rest is longer than input")
+		}
+	})
+}
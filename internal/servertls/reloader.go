@@ -0,0 +1,209 @@
+package servertls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+)
+
+// Reloader отдаёт серверу актуальные сертификат и CA клиентов. Файлы
+// перечитываются при рукопожатии, если с прошлой проверки прошло больше
+// ReloadInterval и у них изменились время модификации или размер. Если новые
+// файлы не читаются (например, ключ ещё не записан), остаются прежние.
+type Reloader struct {
+	cfg        config.ServerTLSConfig
+	minVersion uint16
+	clientAuth tls.ClientAuthType
+	log        *slog.Logger
+
+	mu        sync.Mutex
+	state     *state
+	checkedAt time.Time
+}
+
+type state struct {
+	cert     tls.Certificate
+	clientCA *x509.CertPool
+	files    map[string]fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// New загружает файлы сразу, чтобы ошибка конфигурации проявилась при старте.
+func New(cfg config.ServerTLSConfig, log *slog.Logger) (*Reloader, error) {
+	const op = "servertls.New"
+
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, fmt.Errorf("%s: cert and key files are required", op)
+	}
+	minVersion, err := parseMinVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	clientAuth, err := parseClientAuth(cfg.ClientAuth, cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	r := &Reloader{
+		cfg:        cfg,
+		minVersion: minVersion,
+		clientAuth: clientAuth,
+		log:        log.With(slog.String("component", "servertls")),
+	}
+	st, err := r.load()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	r.state = st
+	r.checkedAt = time.Now()
+
+	return r, nil
+}
+
+// ServerConfig возвращает конфигурацию для сервера с заданными ALPN-протоколами:
+// конфигурация из GetConfigForClient не наследует NextProtos, которые сервер
+// добавил бы сам.
+func (r *Reloader) ServerConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			st := r.current()
+			return &tls.Config{
+				MinVersion:   r.minVersion,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{st.cert},
+				ClientAuth:   r.clientAuth,
+				ClientCAs:    st.clientCA,
+			}, nil
+		},
+	}
+}
+
+func (r *Reloader) current() *state {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) < r.cfg.ReloadInterval {
+		return r.state
+	}
+	r.checkedAt = time.Now()
+
+	if !r.changed() {
+		return r.state
+	}
+
+	st, err := r.load()
+	if err != nil {
+		r.log.Warn("failed to reload tls certificates, keeping previous ones", slog.Any("error", err))
+		return r.state
+	}
+	r.state = st
+	r.log.Info("tls certificates reloaded", slog.String("cert", r.cfg.CertFile))
+
+	return r.state
+}
+
+func (r *Reloader) paths() []string {
+	paths := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		paths = append(paths, r.cfg.ClientCAFile)
+	}
+	return paths
+}
+
+func (r *Reloader) changed() bool {
+	for _, path := range r.paths() {
+		stamp, err := stat(path)
+		if err != nil || stamp != r.state.files[path] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) load() (*state, error) {
+	st := &state{files: make(map[string]fileStamp)}
+	for _, path := range r.paths() {
+		stamp, err := stat(path)
+		if err != nil {
+			return nil, err
+		}
+		st.files[path] = stamp
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	st.cert = cert
+
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", r.cfg.ClientCAFile)
+		}
+		st.clientCA = pool
+	}
+
+	return st, nil
+}
+
+func stat(path string) (fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+func parseMinVersion(s string) (uint16, error) {
+	switch s {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported min tls version '%s'", s)
+	}
+}
+
+func parseClientAuth(mode, caFile string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(mode) {
+	case "":
+		if caFile != "" {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.NoClientCert, nil
+	case "none":
+		return tls.NoClientCert, nil
+	case "optional":
+		if caFile == "" {
+			return 0, errors.New("client auth 'optional' requires a client ca file")
+		}
+		return tls.VerifyClientCertIfGiven, nil
+	case "require":
+		if caFile == "" {
+			return 0, errors.New("client auth 'require' requires a client ca file")
+		}
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, fmt.Errorf("unsupported client auth mode '%s'", mode)
+	}
+}
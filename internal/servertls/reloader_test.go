@@ -0,0 +1,236 @@
+package servertls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+)
+
+func TestReloader(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ca := newTestCA(t)
+
+	dir := t.TempDir()
+	cfg := config.ServerTLSConfig{
+		CertFile:       filepath.Join(dir, "server.crt"),
+		KeyFile:        filepath.Join(dir, "server.key"),
+		ClientCAFile:   filepath.Join(dir, "ca.crt"),
+		MinVersion:     "1.2",
+		ReloadInterval: 0,
+	}
+	writePEM(t, cfg.ClientCAFile, "CERTIFICATE", ca.cert.Raw)
+	ca.issueServer(t, 1, cfg.CertFile, cfg.KeyFile)
+
+	r, err := New(cfg, logger)
+	require.NoError(t, err)
+	addr := serveTLS(t, r.ServerConfig("h2"))
+
+	clientCert := ca.issueClient(t)
+	dial := func(cert *tls.Certificate) (*tls.ConnectionState, error) {
+		clientCfg := &tls.Config{RootCAs: ca.pool, ServerName: "localhost", NextProtos: []string{"h2"}}
+		if cert != nil {
+			clientCfg.Certificates = []tls.Certificate{*cert}
+		}
+		conn, err := tls.Dial("tcp", addr, clientCfg)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+		// В TLS 1.3 сервер сообщает об отказе в сертификате клиента уже после рукопожатия.
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err := conn.Read(make([]byte, 1)); err != nil && !isTimeout(err) {
+			return nil, err
+		}
+		st := conn.ConnectionState()
+		return &st, nil
+	}
+
+	t.Run("mTLS handshake", func(t *testing.T) {
+		st, err := dial(&clientCert)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), st.PeerCertificates[0].SerialNumber.Int64())
+		assert.Equal(t, "h2", st.NegotiatedProtocol)
+	})
+
+	t.Run("Client certificate required", func(t *testing.T) {
+		_, err := dial(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Certificate reloaded from disk", func(t *testing.T) {
+		ca.issueServer(t, 2, cfg.CertFile, cfg.KeyFile)
+
+		st, err := dial(&clientCert)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), st.PeerCertificates[0].SerialNumber.Int64())
+	})
+
+	t.Run("Broken files keep previous certificate", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(cfg.KeyFile, []byte("partial"), 0o600))
+
+		st, err := dial(&clientCert)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), st.PeerCertificates[0].SerialNumber.Int64())
+	})
+}
+
+func TestReloader_MinVersion(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ca := newTestCA(t)
+
+	dir := t.TempDir()
+	cfg := config.ServerTLSConfig{
+		CertFile:   filepath.Join(dir, "server.crt"),
+		KeyFile:    filepath.Join(dir, "server.key"),
+		MinVersion: "1.3",
+	}
+	ca.issueServer(t, 1, cfg.CertFile, cfg.KeyFile)
+
+	r, err := New(cfg, logger)
+	require.NoError(t, err)
+	addr := serveTLS(t, r.ServerConfig())
+
+	_, err = tls.Dial("tcp", addr, &tls.Config{RootCAs: ca.pool, ServerName: "localhost", MaxVersion: tls.VersionTLS12})
+	assert.Error(t, err)
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: ca.pool, ServerName: "localhost"})
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), conn.ConnectionState().Version)
+	conn.Close()
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	ca.issueServer(t, 1, certFile, keyFile)
+
+	cases := map[string]config.ServerTLSConfig{
+		"Missing key":          {CertFile: certFile},
+		"Unknown min version":  {CertFile: certFile, KeyFile: keyFile, MinVersion: "1.1"},
+		"Require without CA":   {CertFile: certFile, KeyFile: keyFile, ClientAuth: "require"},
+		"Unknown client auth":  {CertFile: certFile, KeyFile: keyFile, ClientAuth: "always"},
+		"Missing client CA":    {CertFile: certFile, KeyFile: keyFile, ClientCAFile: filepath.Join(dir, "none.crt")},
+		"Missing cert on disk": {CertFile: filepath.Join(dir, "none.crt"), KeyFile: keyFile},
+	}
+	for name, cfg := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(cfg, logger)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// serveTLS принимает соединения и завершает рукопожатие, пока идёт тест.
+func serveTLS(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+	l, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = conn.(*tls.Conn).Handshake()
+				_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+				_, _ = conn.Read(make([]byte, 1))
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(100),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+func (ca *testCA) issue(t *testing.T, serial int64, usage x509.ExtKeyUsage) ([]byte, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	return der, key
+}
+
+func (ca *testCA) issueServer(t *testing.T, serial int64, certFile, keyFile string) {
+	t.Helper()
+	der, key := ca.issue(t, serial, x509.ExtKeyUsageServerAuth)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "PRIVATE KEY", keyDER)
+}
+
+func (ca *testCA) issueClient(t *testing.T) tls.Certificate {
+	t.Helper()
+	der, key := ca.issue(t, 50, x509.ExtKeyUsageClientAuth)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
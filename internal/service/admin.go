@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+var (
+	ErrReplayNotFound       = errors.New("replay not found")
+	ErrInvalidReplayRequest = errors.New("either from_timestamp or offsets must be provided")
+	ErrTooManyReplays       = errors.New("too many running replays")
+	ErrUnknownReplayTopic   = errors.New("topic is not consumed by the service")
+	ErrReplayTopicRequired  = errors.New("topic must be provided when several topics are consumed")
+)
+
+// GroupInspector сообщает состояние consumer group, из которой читает сервис.
+type GroupInspector interface {
+	GroupStatus(ctx context.Context) (*model.ConsumerGroupStatus, error)
+}
+
+// Replayer повторно обрабатывает записи входного топика, сообщая прогресс через report.
+// Topic проверяет топик запроса и возвращает тот, что будет обработан.
+type Replayer interface {
+	Topic(requested string) (string, error)
+	Replay(ctx context.Context, req model.ReplayRequest, report func(model.ReplayProgress)) error
+}
+
+type replayJob struct {
+	job    model.ReplayJob
+	cancel context.CancelFunc
+}
+
+// Admin объединяет операции для дежурных: диагностику и управление конвейером.
+type Admin struct {
+	ctx       context.Context
+	logger    *slog.Logger
+	inspector GroupInspector
+	replayer  Replayer
+
+	// maxRunning ограничивает число одновременных повторных обработок,
+	// retention — сколько завершённая задача остаётся доступной по id.
+	maxRunning int
+	retention  time.Duration
+
+	mu      sync.Mutex
+	replays map[string]*replayJob
+	running int
+	sweptAt time.Time
+}
+
+// NewAdmin создаёт Admin; ctx ограничивает время жизни запущенных повторных обработок.
+// maxRunning <= 0 снимает ограничение, retention <= 0 хранит завершённые задачи бессрочно.
+func NewAdmin(
+	ctx context.Context,
+	logger *slog.Logger,
+	inspector GroupInspector,
+	replayer Replayer,
+	maxRunning int,
+	retention time.Duration,
+) *Admin {
+	return &Admin{
+		ctx:        ctx,
+		logger:     logger,
+		inspector:  inspector,
+		replayer:   replayer,
+		maxRunning: maxRunning,
+		retention:  retention,
+		replays:    make(map[string]*replayJob),
+	}
+}
+
+func (a *Admin) ConsumerGroupStatus(ctx context.Context) (*model.ConsumerGroupStatus, error) {
+	return a.inspector.GroupStatus(ctx)
+}
+
+// StartReplay запускает повторную обработку в фоне и сразу возвращает задачу.
+func (a *Admin) StartReplay(req model.ReplayRequest) (*model.ReplayJob, error) {
+	if (req.FromTimestamp == nil) == (len(req.Offsets) == 0) {
+		return nil, ErrInvalidReplayRequest
+	}
+	topic, err := a.replayer.Topic(req.Topic)
+	if err != nil {
+		return nil, err
+	}
+	req.Topic = topic
+
+	a.mu.Lock()
+	now := time.Now().UTC()
+	a.sweep(now)
+	if a.maxRunning > 0 && a.running >= a.maxRunning {
+		a.mu.Unlock()
+		return nil, ErrTooManyReplays
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	rj := &replayJob{
+		job: model.ReplayJob{
+			ID:        uuid.New().String(),
+			Topic:     topic,
+			State:     model.ReplayRunning,
+			Request:   req,
+			StartedAt: now,
+		},
+		cancel: cancel,
+	}
+	a.replays[rj.job.ID] = rj
+	a.running++
+	job := rj.job
+	a.mu.Unlock()
+
+	log := a.logger.With(slog.String("replay_id", job.ID))
+	log.Info("replay started")
+
+	go func() {
+		defer cancel()
+
+		err := a.replayer.Replay(ctx, req, func(p model.ReplayProgress) {
+			a.mu.Lock()
+			rj.job.Progress = p
+			a.mu.Unlock()
+		})
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		a.running--
+		now := time.Now().UTC()
+		rj.job.FinishedAt = &now
+		switch {
+		case err == nil:
+			rj.job.State = model.ReplayCompleted
+		case errors.Is(err, context.Canceled):
+			rj.job.State = model.ReplayCancelled
+		default:
+			rj.job.State = model.ReplayFailed
+			rj.job.Error = err.Error()
+		}
+
+		log.Info("replay finished",
+			slog.String("state", string(rj.job.State)),
+			slog.Int64("processed", rj.job.Progress.Processed),
+			slog.Int64("failed", rj.job.Progress.Failed),
+			slog.Any("error", err),
+		)
+	}()
+
+	return &job, nil
+}
+
+func (a *Admin) Replay(id string) (*model.ReplayJob, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.sweep(time.Now().UTC())
+
+	rj, ok := a.replays[id]
+	if !ok {
+		return nil, ErrReplayNotFound
+	}
+	job := rj.job
+	return &job, nil
+}
+
+// CancelReplay останавливает задачу; итоговое состояние появится после её завершения.
+func (a *Admin) CancelReplay(id string) (*model.ReplayJob, error) {
+	a.mu.Lock()
+	rj, ok := a.replays[id]
+	a.mu.Unlock()
+	if !ok {
+		return nil, ErrReplayNotFound
+	}
+
+	rj.cancel()
+	return a.Replay(id)
+}
+
+// sweep удаляет задачи, завершённые раньше чем retention назад. Проход по
+// всем задачам делается не чаще раза в retention; вызывается под a.mu.
+func (a *Admin) sweep(now time.Time) {
+	if a.retention <= 0 || now.Sub(a.sweptAt) < a.retention {
+		return
+	}
+	a.sweptAt = now
+
+	for id, rj := range a.replays {
+		if rj.job.FinishedAt != nil && now.Sub(*rj.job.FinishedAt) > a.retention {
+			delete(a.replays, id)
+		}
+	}
+}
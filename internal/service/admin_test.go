@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin_StartReplay(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	from := time.Now().Add(-time.Hour)
+
+	t.Run("Invalid request", func(t *testing.T) {
+		admin := NewAdmin(ctx, logger, &mocks.MockGroupInspector{}, &mocks.MockReplayer{}, 0, 0)
+
+		_, err := admin.StartReplay(model.ReplayRequest{})
+		assert.ErrorIs(t, err, ErrInvalidReplayRequest)
+
+		_, err = admin.StartReplay(model.ReplayRequest{FromTimestamp: &from, Offsets: map[int32]int64{0: 1}})
+		assert.ErrorIs(t, err, ErrInvalidReplayRequest)
+	})
+
+	t.Run("Completed", func(t *testing.T) {
+		replayer := &mocks.MockReplayer{
+			ReplayFunc: func(ctx context.Context, req model.ReplayRequest, report func(model.ReplayProgress)) error {
+				report(model.ReplayProgress{Processed: 10, Failed: 1})
+				return nil
+			},
+		}
+		admin := NewAdmin(ctx, logger, &mocks.MockGroupInspector{}, replayer, 0, 0)
+
+		job, err := admin.StartReplay(model.ReplayRequest{FromTimestamp: &from})
+		require.NoError(t, err)
+		assert.Equal(t, model.ReplayRunning, job.State)
+
+		require.Eventually(t, func() bool {
+			j, err := admin.Replay(job.ID)
+			return err == nil && j.State == model.ReplayCompleted
+		}, time.Second, 10*time.Millisecond)
+
+		j, _ := admin.Replay(job.ID)
+		assert.Equal(t, int64(10), j.Progress.Processed)
+		assert.Equal(t, int64(1), j.Progress.Failed)
+		assert.NotNil(t, j.FinishedAt)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		replayer := &mocks.MockReplayer{
+			ReplayFunc: func(ctx context.Context, req model.ReplayRequest, report func(model.ReplayProgress)) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+		admin := NewAdmin(ctx, logger, &mocks.MockGroupInspector{}, replayer, 0, 0)
+
+		job, err := admin.StartReplay(model.ReplayRequest{Offsets: map[int32]int64{0: 0}})
+		require.NoError(t, err)
+
+		_, err = admin.CancelReplay(job.ID)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			j, err := admin.Replay(job.ID)
+			return err == nil && j.State == model.ReplayCancelled
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Topic is resolved and stored on the job", func(t *testing.T) {
+		got := make(chan string, 1)
+		replayer := &mocks.MockReplayer{
+			TopicFunc: func(requested string) (string, error) {
+				assert.Empty(t, requested)
+				return "records", nil
+			},
+			ReplayFunc: func(ctx context.Context, req model.ReplayRequest, report func(model.ReplayProgress)) error {
+				got <- req.Topic
+				return nil
+			},
+		}
+		admin := NewAdmin(ctx, logger, &mocks.MockGroupInspector{}, replayer, 0, 0)
+
+		job, err := admin.StartReplay(model.ReplayRequest{FromTimestamp: &from})
+		require.NoError(t, err)
+		assert.Equal(t, "records", job.Topic)
+		assert.Equal(t, "records", <-got)
+	})
+
+	t.Run("Unknown topic", func(t *testing.T) {
+		replayer := &mocks.MockReplayer{
+			TopicFunc: func(requested string) (string, error) {
+				return "", ErrUnknownReplayTopic
+			},
+		}
+		admin := NewAdmin(ctx, logger, &mocks.MockGroupInspector{}, replayer, 0, 0)
+
+		_, err := admin.StartReplay(model.ReplayRequest{Topic: "other", FromTimestamp: &from})
+		assert.ErrorIs(t, err, ErrUnknownReplayTopic)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		admin := NewAdmin(ctx, logger, &mocks.MockGroupInspector{}, &mocks.MockReplayer{}, 0, 0)
+
+		_, err := admin.Replay("missing")
+		assert.ErrorIs(t, err, ErrReplayNotFound)
+	})
+}
+
+func TestAdmin_ReplayLimits(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	from := time.Now().Add(-time.Hour)
+
+	t.Run("Too many running replays", func(t *testing.T) {
+		release := make(chan struct{})
+		replayer := &mocks.MockReplayer{
+			ReplayFunc: func(ctx context.Context, req model.ReplayRequest, report func(model.ReplayProgress)) error {
+				<-release
+				return nil
+			},
+		}
+		admin := NewAdmin(ctx, logger, &mocks.MockGroupInspector{}, replayer, 2, 0)
+
+		first, err := admin.StartReplay(model.ReplayRequest{FromTimestamp: &from})
+		require.NoError(t, err)
+		_, err = admin.StartReplay(model.ReplayRequest{FromTimestamp: &from})
+		require.NoError(t, err)
+
+		_, err = admin.StartReplay(model.ReplayRequest{FromTimestamp: &from})
+		assert.ErrorIs(t, err, ErrTooManyReplays)
+
+		close(release)
+		require.Eventually(t, func() bool {
+			j, err := admin.Replay(first.ID)
+			return err == nil && j.State == model.ReplayCompleted
+		}, time.Second, 10*time.Millisecond)
+
+		require.Eventually(t, func() bool {
+			_, err := admin.StartReplay(model.ReplayRequest{FromTimestamp: &from})
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Finished replays expire", func(t *testing.T) {
+		admin := NewAdmin(ctx, logger, &mocks.MockGroupInspector{}, &mocks.MockReplayer{}, 0, 50*time.Millisecond)
+
+		job, err := admin.StartReplay(model.ReplayRequest{FromTimestamp: &from})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			_, err := admin.Replay(job.ID)
+			return errors.Is(err, ErrReplayNotFound)
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Running replays are kept", func(t *testing.T) {
+		replayer := &mocks.MockReplayer{
+			ReplayFunc: func(ctx context.Context, req model.ReplayRequest, report func(model.ReplayProgress)) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+		admin := NewAdmin(ctx, logger, &mocks.MockGroupInspector{}, replayer, 0, 10*time.Millisecond)
+
+		job, err := admin.StartReplay(model.ReplayRequest{FromTimestamp: &from})
+		require.NoError(t, err)
+		t.Cleanup(func() { _, _ = admin.CancelReplay(job.ID) })
+
+		time.Sleep(50 * time.Millisecond)
+		j, err := admin.Replay(job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ReplayRunning, j.State)
+	})
+}
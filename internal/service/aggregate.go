@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+// Поддерживаемые агрегаты. Каждый вычисляется по значениям одной записи;
+// max привязок с тегом по умолчанию сохраняется в max_values, остальное —
+// в таблицу aggregates.
+const (
+	AggregatorMax   = "max"
+	AggregatorMin   = "min"
+	AggregatorSum   = "sum"
+	AggregatorCount = "count"
+)
+
+var aggregators = map[string]func(values []int64) int64{
+	AggregatorMax:   computeMax,
+	AggregatorMin:   computeMin,
+	AggregatorSum:   computeSum,
+	AggregatorCount: func(values []int64) int64 { return int64(len(values)) },
+}
+
+// CheckAggregator возвращает ошибку для неизвестного имени агрегата.
+func CheckAggregator(name string) error {
+	if _, ok := aggregators[name]; !ok {
+		return fmt.Errorf("unknown aggregator %q", name)
+	}
+	return nil
+}
+
+// Compute вычисляет агрегат name, проверенный через CheckAggregator.
+func (s *Service) Compute(name string, values []int64) int64 {
+	return aggregators[name](values)
+}
+
+func (s *Service) SaveAggregates(ctx context.Context, rec model.AggregateRecord) error {
+	return s.pgxrepo.SaveAggregates(ctx, &rec)
+}
+
+func computeMin(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	minV := values[0]
+	for _, v := range values[1:] {
+		if v < minV {
+			minV = v
+		}
+	}
+	return minV
+}
+
+func computeSum(values []int64) int64 {
+	var sum int64
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+// MockGroupInspector is a mock implementation of the GroupInspector interface.
+type MockGroupInspector struct {
+	GroupStatusFunc func(ctx context.Context) (*model.ConsumerGroupStatus, error)
+}
+
+func (m *MockGroupInspector) GroupStatus(ctx context.Context) (*model.ConsumerGroupStatus, error) {
+	if m.GroupStatusFunc != nil {
+		return m.GroupStatusFunc(ctx)
+	}
+	return nil, nil
+}
+
+// MockReplayer is a mock implementation of the Replayer interface.
+type MockReplayer struct {
+	TopicFunc  func(requested string) (string, error)
+	ReplayFunc func(ctx context.Context, req model.ReplayRequest, report func(model.ReplayProgress)) error
+}
+
+func (m *MockReplayer) Topic(requested string) (string, error) {
+	if m.TopicFunc != nil {
+		return m.TopicFunc(requested)
+	}
+	return requested, nil
+}
+
+func (m *MockReplayer) Replay(ctx context.Context, req model.ReplayRequest, report func(model.ReplayProgress)) error {
+	if m.ReplayFunc != nil {
+		return m.ReplayFunc(ctx, req, report)
+	}
+	return nil
+}
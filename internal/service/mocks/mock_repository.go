@@ -10,16 +10,30 @@ import (
 // MockMaxValueRepository is a mock implementation of the MaxValueRepository interface.
 // It allows for setting expected return values for testing purposes.
 type MockMaxValueRepository struct {
-	SaveMaxFunc        func(ctx context.Context, rec *model.MaxValueRecord) error
+	SaveMaxFunc        func(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error)
 	GetMaxByIDFunc     func(ctx context.Context, uuid string) (*model.MaxValue, error)
 	GetMaxByPeriodFunc func(ctx context.Context, from, to time.Time) ([]model.MaxValue, error)
+	GetMaxAsOfFunc     func(ctx context.Context, uuid string, asOf time.Time) (*model.MaxValue, error)
+	ListRevisionsFunc  func(ctx context.Context, uuid string) ([]model.MaxValueRevision, error)
+	SaveAggregatesFunc func(ctx context.Context, rec *model.AggregateRecord) error
+	GetRecordFunc      func(ctx context.Context, uuid string) (*model.Record, error)
+	GetRecordAsOfFunc  func(ctx context.Context, uuid string, asOf time.Time) (*model.Record, error)
+	ListRecordsFunc    func(ctx context.Context, q model.RecordQuery) ([]model.Record, error)
+	ListSeriesFunc     func(ctx context.Context, uuid string, from, to time.Time) ([]model.MaxValueRevision, error)
 }
 
-func (m *MockMaxValueRepository) SaveMax(ctx context.Context, rec *model.MaxValueRecord) error {
+func (m *MockMaxValueRepository) SaveAggregates(ctx context.Context, rec *model.AggregateRecord) error {
+	if m.SaveAggregatesFunc != nil {
+		return m.SaveAggregatesFunc(ctx, rec)
+	}
+	return nil
+}
+
+func (m *MockMaxValueRepository) SaveMax(ctx context.Context, rec *model.MaxValueRecord) (*model.MaxValueResult, error) {
 	if m.SaveMaxFunc != nil {
 		return m.SaveMaxFunc(ctx, rec)
 	}
-	return nil
+	return nil, nil
 }
 
 func (m *MockMaxValueRepository) GetMaxByID(ctx context.Context, uuid string) (*model.MaxValue, error) {
@@ -35,3 +49,45 @@ func (m *MockMaxValueRepository) GetMaxByPeriod(ctx context.Context, from, to ti
 	}
 	return nil, nil
 }
+
+func (m *MockMaxValueRepository) GetMaxAsOf(ctx context.Context, uuid string, asOf time.Time) (*model.MaxValue, error) {
+	if m.GetMaxAsOfFunc != nil {
+		return m.GetMaxAsOfFunc(ctx, uuid, asOf)
+	}
+	return nil, nil
+}
+
+func (m *MockMaxValueRepository) ListRevisions(ctx context.Context, uuid string) ([]model.MaxValueRevision, error) {
+	if m.ListRevisionsFunc != nil {
+		return m.ListRevisionsFunc(ctx, uuid)
+	}
+	return nil, nil
+}
+
+func (m *MockMaxValueRepository) GetRecord(ctx context.Context, uuid string) (*model.Record, error) {
+	if m.GetRecordFunc != nil {
+		return m.GetRecordFunc(ctx, uuid)
+	}
+	return nil, nil
+}
+
+func (m *MockMaxValueRepository) GetRecordAsOf(ctx context.Context, uuid string, asOf time.Time) (*model.Record, error) {
+	if m.GetRecordAsOfFunc != nil {
+		return m.GetRecordAsOfFunc(ctx, uuid, asOf)
+	}
+	return nil, nil
+}
+
+func (m *MockMaxValueRepository) ListRecords(ctx context.Context, q model.RecordQuery) ([]model.Record, error) {
+	if m.ListRecordsFunc != nil {
+		return m.ListRecordsFunc(ctx, q)
+	}
+	return nil, nil
+}
+
+func (m *MockMaxValueRepository) ListSeries(ctx context.Context, uuid string, from, to time.Time) ([]model.MaxValueRevision, error) {
+	if m.ListSeriesFunc != nil {
+		return m.ListSeriesFunc(ctx, uuid, from, to)
+	}
+	return nil, nil
+}
@@ -18,8 +18,19 @@ func New(logger *slog.Logger, pgxrepo repository.MaxValueRepository) *Service {
 	return &Service{logger: logger, pgxrepo: pgxrepo}
 }
 
-func (s *Service) SaveMaxValue(ctx context.Context, rec model.MaxValueRecord) error {
-	return s.pgxrepo.SaveMax(ctx, &rec)
+// SaveMaxValue возвращает nil без ошибки, если запись отброшена политикой конфликтов.
+// Происхождение записи из ctx (см. tracing.Metadata) попадает в логи сохранения.
+func (s *Service) SaveMaxValue(ctx context.Context, rec model.MaxValueRecord) (*model.MaxValueResult, error) {
+	res, err := s.pgxrepo.SaveMax(ctx, &rec)
+	if err != nil || res == nil {
+		return res, err
+	}
+
+	s.logger.DebugContext(ctx, "max value saved",
+		slog.String("uuid", res.UUID),
+		slog.Int64("version", res.Version),
+	)
+	return res, nil
 }
 
 func (s *Service) GetMaxByID(ctx context.Context, uuid string) (*model.MaxValue, error) {
@@ -30,7 +41,50 @@ func (s *Service) GetMaxByPeriod(ctx context.Context, from, to time.Time) ([]mod
 	return s.pgxrepo.GetMaxByPeriod(ctx, from, to)
 }
 
+func (s *Service) GetMaxAsOf(ctx context.Context, uuid string, asOf time.Time) (*model.MaxValue, error) {
+	return s.pgxrepo.GetMaxAsOf(ctx, uuid, asOf)
+}
+
+func (s *Service) ListRevisions(ctx context.Context, uuid string) ([]model.MaxValueRevision, error) {
+	return s.pgxrepo.ListRevisions(ctx, uuid)
+}
+
+func (s *Service) GetRecord(ctx context.Context, uuid string) (*model.Record, error) {
+	return s.pgxrepo.GetRecord(ctx, uuid)
+}
+
+func (s *Service) GetRecordAsOf(ctx context.Context, uuid string, asOf time.Time) (*model.Record, error) {
+	return s.pgxrepo.GetRecordAsOf(ctx, uuid, asOf)
+}
+
+// ListRecords возвращает страницу записей и курсор следующей страницы;
+// на последней странице курсор nil.
+func (s *Service) ListRecords(ctx context.Context, q model.RecordQuery) ([]model.Record, *model.RecordCursor, error) {
+	limit := q.Limit
+	q.Limit++ // лишняя запись показывает, что страница не последняя
+
+	records, err := s.pgxrepo.ListRecords(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) <= limit {
+		return records, nil, nil
+	}
+
+	records = records[:limit]
+	last := records[limit-1]
+	return records, &model.RecordCursor{Timestamp: last.Timestamp, UUID: last.UUID}, nil
+}
+
+func (s *Service) ListSeries(ctx context.Context, uuid string, from, to time.Time) ([]model.MaxValueRevision, error) {
+	return s.pgxrepo.ListSeries(ctx, uuid, from, to)
+}
+
 func (s *Service) ComputeMax(values []int64) int64 {
+	return computeMax(values)
+}
+
+func computeMax(values []int64) int64 {
 	if len(values) == 0 {
 		return 0
 	}
@@ -96,3 +96,100 @@ func TestService_GetMaxByPeriod(t *testing.T) {
 		assert.Empty(t, records)
 	})
 }
+
+func TestService_GetMaxAsOf(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	testUUID := "test-uuid-123"
+	asOf := time.Now().Add(-24 * time.Hour)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := &mocks.MockMaxValueRepository{
+			GetMaxAsOfFunc: func(ctx context.Context, uuid string, at time.Time) (*model.MaxValue, error) {
+				assert.Equal(t, testUUID, uuid)
+				assert.True(t, asOf.Equal(at))
+				return &model.MaxValue{Value: 42}, nil
+			},
+		}
+		service := New(logger, mockRepo)
+
+		record, err := service.GetMaxAsOf(ctx, testUUID, asOf)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), record.Value)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockRepo := &mocks.MockMaxValueRepository{
+			GetMaxAsOfFunc: func(ctx context.Context, uuid string, at time.Time) (*model.MaxValue, error) {
+				return nil, repository.ErrNotFound
+			},
+		}
+		service := New(logger, mockRepo)
+
+		record, err := service.GetMaxAsOf(ctx, testUUID, asOf)
+
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		assert.Nil(t, record)
+	})
+}
+
+func TestService_ListRevisions(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	testUUID := "test-uuid-123"
+	expected := []model.MaxValueRevision{
+		{UUID: testUUID, Version: 1, MaxValue: 10},
+		{UUID: testUUID, Version: 2, MaxValue: 20},
+	}
+
+	mockRepo := &mocks.MockMaxValueRepository{
+		ListRevisionsFunc: func(ctx context.Context, uuid string) ([]model.MaxValueRevision, error) {
+			assert.Equal(t, testUUID, uuid)
+			return expected, nil
+		},
+	}
+	service := New(logger, mockRepo)
+
+	revisions, err := service.ListRevisions(ctx, testUUID)
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, revisions)
+}
+
+func TestService_ListRecords(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []model.Record{
+		{UUID: "a", Timestamp: base, MaxValue: 1},
+		{UUID: "b", Timestamp: base.Add(time.Minute), MaxValue: 2},
+		{UUID: "c", Timestamp: base.Add(2 * time.Minute), MaxValue: 3},
+	}
+	mockRepo := &mocks.MockMaxValueRepository{
+		ListRecordsFunc: func(ctx context.Context, q model.RecordQuery) ([]model.Record, error) {
+			return rows[:min(q.Limit, len(rows))], nil
+		},
+	}
+	service := New(logger, mockRepo)
+
+	t.Run("Next page", func(t *testing.T) {
+		records, next, err := service.ListRecords(ctx, model.RecordQuery{Limit: 2})
+
+		require.NoError(t, err)
+		assert.Equal(t, rows[:2], records)
+		require.NotNil(t, next)
+		assert.Equal(t, model.RecordCursor{Timestamp: rows[1].Timestamp, UUID: "b"}, *next)
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		records, next, err := service.ListRecords(ctx, model.RecordQuery{Limit: 3})
+
+		require.NoError(t, err)
+		assert.Equal(t, rows, records)
+		assert.Nil(t, next)
+	})
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Metadata — происхождение записи: контекст трассировки и сведения,
+// которые продюсер передаёт в заголовках Kafka.
+type Metadata struct {
+	TraceParent TraceParent
+	ProducerID  string
+	ContentType string
+	IngestedAt  time.Time
+}
+
+type metadataKey struct{}
+
+func WithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+func FromContext(ctx context.Context) (Metadata, bool) {
+	md, ok := ctx.Value(metadataKey{}).(Metadata)
+	return md, ok
+}
+
+// LogAttrs возвращает заполненные поля для логов.
+func (m Metadata) LogAttrs() []slog.Attr {
+	var attrs []slog.Attr
+	if m.TraceParent.IsValid() {
+		attrs = append(attrs,
+			slog.String("trace_id", m.TraceParent.TraceIDString()),
+			slog.String("span_id", m.TraceParent.SpanIDString()),
+		)
+	}
+	if m.ProducerID != "" {
+		attrs = append(attrs, slog.String("producer_id", m.ProducerID))
+	}
+	if m.ContentType != "" {
+		attrs = append(attrs, slog.String("content_type", m.ContentType))
+	}
+	if !m.IngestedAt.IsZero() {
+		attrs = append(attrs, slog.Time("ingested_at", m.IngestedAt))
+	}
+	return attrs
+}
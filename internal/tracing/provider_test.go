@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+)
+
+func TestSetup(t *testing.T) {
+	ctx := context.Background()
+	// Setup заменяет глобальный провайдер; прежний вернуть нельзя — GetTracerProvider
+	// до первой установки отдаёт делегата, поэтому после теста ставится no-op.
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	t.Run("File exporter", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "traces.jsonl")
+		tp, shutdown, err := Setup(ctx, config.TracingConfig{Exporter: "file", File: path, ServiceName: "test", SampleRatio: 1})
+		require.NoError(t, err)
+
+		_, span := tp.Tracer("test").Start(ctx, "exported")
+		span.End()
+		require.NoError(t, shutdown(ctx))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"Name":"exported"`)
+	})
+
+	t.Run("No exporter still creates trace ids", func(t *testing.T) {
+		tp, shutdown, err := Setup(ctx, config.TracingConfig{Exporter: "none", SampleRatio: 0})
+		require.NoError(t, err)
+		defer shutdown(ctx)
+
+		_, span := tp.Tracer("test").Start(ctx, "local")
+		defer span.End()
+		assert.True(t, span.SpanContext().IsValid())
+	})
+
+	t.Run("Unknown exporter", func(t *testing.T) {
+		_, _, err := Setup(ctx, config.TracingConfig{Exporter: "zipkin"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrInvalidTraceParent = errors.New("invalid traceparent")
+
+// TraceParent — контекст трассировки в формате W3C Trace Context
+// (заголовок traceparent: версия, trace-id, parent-id, флаги).
+type TraceParent struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+}
+
+// flagSampled — единственный определённый стандартом флаг.
+const flagSampled = 0x01
+
+// NewTraceParent начинает новую трассировку.
+func NewTraceParent() TraceParent {
+	var tp TraceParent
+	_, _ = rand.Read(tp.TraceID[:])
+	_, _ = rand.Read(tp.SpanID[:])
+	tp.Flags = flagSampled
+	return tp
+}
+
+// ParseTraceParent разбирает заголовок traceparent. Заголовки будущих версий
+// принимаются, если их начало совпадает с форматом версии 00.
+func ParseTraceParent(s string) (TraceParent, error) {
+	var tp TraceParent
+
+	// 00-<32 hex>-<16 hex>-<2 hex>
+	const length = 55
+	if len(s) < length || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return tp, ErrInvalidTraceParent
+	}
+
+	var version [1]byte
+	if _, err := hex.Decode(version[:], []byte(s[:2])); err != nil || version[0] == 0xff {
+		return tp, ErrInvalidTraceParent
+	}
+	if version[0] == 0 && len(s) != length {
+		return tp, ErrInvalidTraceParent
+	}
+	if len(s) > length && s[length] != '-' {
+		return tp, ErrInvalidTraceParent
+	}
+
+	var flags [1]byte
+	if _, err := hex.Decode(tp.TraceID[:], []byte(s[3:35])); err != nil {
+		return tp, fmt.Errorf("%w: trace-id: %w", ErrInvalidTraceParent, err)
+	}
+	if _, err := hex.Decode(tp.SpanID[:], []byte(s[36:52])); err != nil {
+		return tp, fmt.Errorf("%w: parent-id: %w", ErrInvalidTraceParent, err)
+	}
+	if _, err := hex.Decode(flags[:], []byte(s[53:55])); err != nil {
+		return tp, fmt.Errorf("%w: flags: %w", ErrInvalidTraceParent, err)
+	}
+	tp.Flags = flags[0]
+
+	if !tp.IsValid() {
+		return tp, ErrInvalidTraceParent
+	}
+	return tp, nil
+}
+
+// IsValid сообщает, что trace-id и parent-id не нулевые.
+func (tp TraceParent) IsValid() bool {
+	return tp.TraceID != [16]byte{} && tp.SpanID != [8]byte{}
+}
+
+// Child возвращает контекст новой операции в той же трассировке.
+func (tp TraceParent) Child() TraceParent {
+	_, _ = rand.Read(tp.SpanID[:])
+	return tp
+}
+
+func (tp TraceParent) TraceIDString() string { return hex.EncodeToString(tp.TraceID[:]) }
+func (tp TraceParent) SpanIDString() string  { return hex.EncodeToString(tp.SpanID[:]) }
+
+// SpanContext возвращает tp как удалённый родительский спан OpenTelemetry.
+func (tp TraceParent) SpanContext() trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    tp.TraceID,
+		SpanID:     tp.SpanID,
+		TraceFlags: trace.TraceFlags(tp.Flags),
+		Remote:     true,
+	})
+}
+
+func FromSpanContext(sc trace.SpanContext) TraceParent {
+	return TraceParent{TraceID: sc.TraceID(), SpanID: sc.SpanID(), Flags: byte(sc.TraceFlags())}
+}
+
+// String возвращает заголовок traceparent версии 00.
+func (tp TraceParent) String() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tp.TraceIDString(), tp.SpanIDString(), tp.Flags)
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	const valid = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	t.Run("Valid", func(t *testing.T) {
+		tp, err := ParseTraceParent(valid)
+		require.NoError(t, err)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", tp.TraceIDString())
+		assert.Equal(t, "00f067aa0ba902b7", tp.SpanIDString())
+		assert.Equal(t, byte(1), tp.Flags)
+		assert.Equal(t, valid, tp.String())
+	})
+
+	t.Run("Future version", func(t *testing.T) {
+		tp, err := ParseTraceParent("01" + valid[2:] + "-extra")
+		require.NoError(t, err)
+		assert.Equal(t, valid, tp.String())
+	})
+
+	invalid := map[string]string{
+		"Empty":            "",
+		"Too long v00":     valid + "-extra",
+		"Version ff":       "ff" + valid[2:],
+		"Zero trace-id":    "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"Zero parent-id":   "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"Not hex":          "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01",
+		"Wrong separators": "00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01",
+	}
+	for name, s := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseTraceParent(s)
+			assert.ErrorIs(t, err, ErrInvalidTraceParent)
+		})
+	}
+}
+
+func TestTraceParent_Child(t *testing.T) {
+	parent := NewTraceParent()
+	require.True(t, parent.IsValid())
+
+	child := parent.Child()
+	assert.Equal(t, parent.TraceID, child.TraceID)
+	assert.NotEqual(t, parent.SpanID, child.SpanID)
+	assert.Equal(t, parent.Flags, child.Flags)
+}
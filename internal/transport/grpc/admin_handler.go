@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+
+	pb "github.com/Pavel26ru/aggregator-service/gen"
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type AdminHandler struct {
+	pb.UnimplementedAdminServiceServer
+	admin *service.Admin
+	log   *slog.Logger
+}
+
+func NewAdminHandler(a *service.Admin, log *slog.Logger) *AdminHandler {
+	return &AdminHandler{admin: a, log: log}
+}
+
+func (h *AdminHandler) GetConsumerGroupStatus(ctx context.Context, _ *pb.GetConsumerGroupStatusRequest) (*pb.ConsumerGroupStatus, error) {
+	const op = "grpc.GetConsumerGroupStatus"
+	log := h.log.With(slog.String("op", op))
+
+	st, err := h.admin.ConsumerGroupStatus(ctx)
+	if err != nil {
+		return nil, errorStatus(ctx, log, "failed to describe consumer group",
+			apierr.Wrap(apierr.CodeUnavailable, "failed to describe consumer group", err))
+	}
+
+	resp := &pb.ConsumerGroupStatus{
+		Group:    st.Group,
+		State:    st.State,
+		Protocol: st.Protocol,
+		TotalLag: st.TotalLag,
+	}
+	for _, m := range st.Members {
+		member := &pb.GroupMember{
+			MemberId:   m.MemberID,
+			InstanceId: m.InstanceID,
+			ClientId:   m.ClientID,
+			ClientHost: m.ClientHost,
+		}
+		for _, a := range m.Assignments {
+			member.Assignments = append(member.Assignments, &pb.TopicAssignment{
+				Topic:      a.Topic,
+				Partitions: a.Partitions,
+			})
+		}
+		resp.Members = append(resp.Members, member)
+	}
+	for _, p := range st.Partitions {
+		resp.Partitions = append(resp.Partitions, &pb.PartitionLag{
+			Topic:           p.Topic,
+			Partition:       p.Partition,
+			CommittedOffset: p.CommittedOffset,
+			HighWatermark:   p.HighWatermark,
+			Lag:             p.Lag,
+			MemberId:        p.MemberID,
+			Error:           p.Error,
+		})
+	}
+
+	return resp, nil
+}
+
+func (h *AdminHandler) StartReplay(ctx context.Context, req *pb.StartReplayRequest) (*pb.ReplayJob, error) {
+	const op = "grpc.StartReplay"
+	log := h.log.With(slog.String("op", op))
+
+	replayReq := model.ReplayRequest{Topic: req.Topic, Offsets: req.Offsets}
+	if req.FromTimestamp != nil {
+		from := req.FromTimestamp.AsTime()
+		replayReq.FromTimestamp = &from
+	}
+
+	job, err := h.admin.StartReplay(replayReq)
+	if err != nil {
+		return nil, errorStatus(ctx, log, "failed to start replay", err)
+	}
+
+	return replayJobToPB(job), nil
+}
+
+func (h *AdminHandler) GetReplay(ctx context.Context, req *pb.GetReplayRequest) (*pb.ReplayJob, error) {
+	job, err := h.admin.Replay(req.Id)
+	if err != nil {
+		return nil, errorStatus(ctx, h.log, "replay request failed", err)
+	}
+	return replayJobToPB(job), nil
+}
+
+func (h *AdminHandler) CancelReplay(ctx context.Context, req *pb.CancelReplayRequest) (*pb.ReplayJob, error) {
+	job, err := h.admin.CancelReplay(req.Id)
+	if err != nil {
+		return nil, errorStatus(ctx, h.log, "replay request failed", err)
+	}
+	return replayJobToPB(job), nil
+}
+
+func replayJobToPB(job *model.ReplayJob) *pb.ReplayJob {
+	resp := &pb.ReplayJob{
+		Id:        job.ID,
+		Topic:     job.Topic,
+		State:     string(job.State),
+		Processed: job.Progress.Processed,
+		Failed:    job.Progress.Failed,
+		StartedAt: timestamppb.New(job.StartedAt),
+		Error:     job.Error,
+	}
+	if job.FinishedAt != nil {
+		resp.FinishedAt = timestamppb.New(*job.FinishedAt)
+	}
+	for _, p := range job.Progress.Partitions {
+		resp.Partitions = append(resp.Partitions, &pb.ReplayPartitionProgress{
+			Partition:     p.Partition,
+			StartOffset:   p.StartOffset,
+			CurrentOffset: p.CurrentOffset,
+			EndOffset:     p.EndOffset,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,78 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// APIKeyHeader — альтернатива метаданным authorization для API-ключей.
+const APIKeyHeader = "x-api-key"
+
+// MethodScopes возвращает область доступа, нужную для вызова метода.
+type MethodScopes func(fullMethod string) auth.Scope
+
+// AuthUnaryInterceptor и AuthStreamInterceptor проверяют токен из метаданных
+// и кладут клиента в контекст. При authn == nil вызовы не проверяются.
+func AuthUnaryInterceptor(authn *auth.Authenticator, scopes MethodScopes, log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if authn == nil {
+			return handler(ctx, req)
+		}
+		ctx, err := authorize(ctx, authn, scopes(info.FullMethod), info.FullMethod, log)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func AuthStreamInterceptor(authn *auth.Authenticator, scopes MethodScopes, log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if authn == nil {
+			return handler(srv, ss)
+		}
+		ctx, err := authorize(ss.Context(), authn, scopes(info.FullMethod), info.FullMethod, log)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authorize(ctx context.Context, authn *auth.Authenticator, scope auth.Scope, method string, log *slog.Logger) (context.Context, error) {
+	p, err := authn.Authorize(ctx, credentials(ctx), scope)
+	if err != nil {
+		log.WarnContext(ctx, "rpc rejected by auth",
+			slog.String("method", method),
+			slog.String("scope", string(scope)),
+			slog.Any("error", err),
+			slog.String("request_id", RequestID(ctx)),
+		)
+		return ctx, statusError(ctx, apierr.From(err))
+	}
+	return auth.WithPrincipal(ctx, p), nil
+}
+
+// credentials берёт токен из authorization: Bearer или x-api-key.
+func credentials(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		if scheme, token, ok := strings.Cut(values[0], " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if values := md.Get(APIKeyHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+)
+
+// statusError переводит ошибку в статус gRPC. В детали статуса попадают
+// ErrorInfo со стабильным кодом, RequestInfo с идентификатором запроса,
+// BadRequest с нарушениями по полям и extra.
+func statusError(ctx context.Context, e *apierr.Error, extra ...protoadapt.MessageV1) error {
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{Reason: e.Code.Reason(), Domain: apierr.Domain},
+	}
+	if id := RequestID(ctx); id != "" {
+		details = append(details, &errdetails.RequestInfo{RequestId: id})
+	}
+	if len(e.Fields) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range e.Fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Description,
+			})
+		}
+		details = append(details, br)
+	}
+	details = append(details, extra...)
+
+	st := status.New(e.Code.GRPCCode(), e.Message)
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// errorStatus сопоставляет ошибку сервиса с кодом и переводит её в статус gRPC.
+// Внутренние ошибки логируются с исходной причиной под сообщением msg,
+// прерванные запросы — с предупреждением.
+func errorStatus(ctx context.Context, log *slog.Logger, msg string, err error) error {
+	e := apierr.From(err)
+	switch {
+	case e.Internal():
+		log.ErrorContext(ctx, msg, slog.Any("error", err))
+	case e.Interrupted():
+		log.WarnContext(ctx, msg, slog.String("code", string(e.Code)), slog.Any("error", err))
+	default:
+		log.InfoContext(ctx, msg, slog.String("code", string(e.Code)))
+	}
+	return statusError(ctx, e)
+}
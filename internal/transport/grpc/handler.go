@@ -2,14 +2,13 @@ package grpc
 
 import (
 	"context"
-	"errors"
 	"log/slog"
 
 	pb "github.com/Pavel26ru/aggregator-service/gen"
-	"github.com/Pavel26ru/aggregator-service/internal/repository"
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
 	"github.com/Pavel26ru/aggregator-service/internal/service"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type Handler struct {
@@ -28,14 +27,17 @@ func (h *Handler) GetMax(ctx context.Context, req *pb.GetMaxRequest) (*pb.GetMax
 
 	// по UUID
 	if req.Uuid != "" {
-		rec, err := h.service.GetMaxByID(ctx, req.Uuid)
+		var (
+			rec *model.MaxValue
+			err error
+		)
+		if req.AsOf != nil {
+			rec, err = h.service.GetMaxAsOf(ctx, req.Uuid, req.AsOf.AsTime())
+		} else {
+			rec, err = h.service.GetMaxByID(ctx, req.Uuid)
+		}
 		if err != nil {
-			if errors.Is(err, repository.ErrNotFound) {
-				log.Info("record not found by uuid")
-				return nil, status.Error(codes.NotFound, "record not found")
-			}
-			log.Error("failed to get record by id", slog.Any("error", err))
-			return nil, status.Error(codes.Internal, "internal error")
+			return nil, errorStatus(ctx, log, "failed to get record by id", err)
 		}
 
 		return &pb.GetMaxResponse{
@@ -54,8 +56,7 @@ func (h *Handler) GetMax(ctx context.Context, req *pb.GetMaxRequest) (*pb.GetMax
 
 		list, err := h.service.GetMaxByPeriod(ctx, from, to)
 		if err != nil {
-			log.Error("failed to get records by period", slog.Any("error", err))
-			return nil, status.Error(codes.Internal, "internal error")
+			return nil, errorStatus(ctx, log, "failed to get records by period", err)
 		}
 		if len(list) == 0 {
 			log.Info("no records found for the period")
@@ -72,5 +73,32 @@ func (h *Handler) GetMax(ctx context.Context, req *pb.GetMaxRequest) (*pb.GetMax
 	}
 
 	log.Warn("bad request: neither uuid nor period provided")
-	return nil, status.Error(codes.InvalidArgument, "either uuid or a time period must be provided")
+	return nil, statusError(ctx, apierr.New(apierr.CodeInvalidArgument, "either uuid or a time period must be provided"))
+}
+
+func (h *Handler) ListRevisions(ctx context.Context, req *pb.ListRevisionsRequest) (*pb.ListRevisionsResponse, error) {
+	const op = "grpc.ListRevisions"
+	log := h.log.With(slog.String("op", op), slog.String("uuid", req.Uuid))
+
+	if req.Uuid == "" {
+		log.Warn("bad request: uuid not provided")
+		return nil, statusError(ctx, apierr.InvalidArgument("uuid", "must be provided"))
+	}
+
+	list, err := h.service.ListRevisions(ctx, req.Uuid)
+	if err != nil {
+		return nil, errorStatus(ctx, log, "failed to list revisions", err)
+	}
+
+	resp := &pb.ListRevisionsResponse{}
+	for _, rev := range list {
+		resp.Revisions = append(resp.Revisions, &pb.Revision{
+			Uuid:       rev.UUID,
+			Version:    rev.Version,
+			Ts:         timestamppb.New(rev.Timestamp),
+			MaxValue:   rev.MaxValue,
+			RecordedAt: timestamppb.New(rev.RecordedAt),
+		})
+	}
+	return resp, nil
 }
@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDHeader — ключ метаданных с идентификатором запроса,
+// совпадает с заголовком X-Request-Id в REST.
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLength ограничивает идентификатор, пришедший от клиента.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestID возвращает идентификатор запроса, назначенный RequestIDUnaryInterceptor
+// или RequestIDStreamInterceptor.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID берёт идентификатор из входящих метаданных или создаёт новый
+// и возвращает его клиенту в заголовке ответа.
+func withRequestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 && len(values[0]) <= maxRequestIDLength {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestIDUnaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withRequestID(ctx), req)
+}
+
+func RequestIDStreamInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+}
+
+// AccessLogUnaryInterceptor и AccessLogStreamInterceptor — аналог
+// rest.AccessLogMiddleware для gRPC.
+func AccessLogUnaryInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, log, info.FullMethod, err, start)
+		return resp, err
+	}
+}
+
+func AccessLogStreamInterceptor(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), log, info.FullMethod, err, start)
+		return err
+	}
+}
+
+func logCall(ctx context.Context, log *slog.Logger, method string, err error, start time.Time) {
+	var remote string
+	if p, ok := peer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+	}
+
+	log.InfoContext(ctx, "incoming rpc",
+		slog.String("method", method),
+		slog.String("remote", remote),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("request_id", RequestID(ctx)),
+	)
+}
+
+// RecoveryUnaryInterceptor и RecoveryStreamInterceptor превращают панику
+// обработчика в codes.Internal вместо завершения процесса.
+func RecoveryUnaryInterceptor(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer recoverPanic(ctx, log, info.FullMethod, &err)
+		return handler(ctx, req)
+	}
+}
+
+func RecoveryStreamInterceptor(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverPanic(ss.Context(), log, info.FullMethod, &err)
+		return handler(srv, ss)
+	}
+}
+
+func recoverPanic(ctx context.Context, log *slog.Logger, method string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	log.ErrorContext(ctx, "panic in grpc handler",
+		slog.String("method", method),
+		slog.String("panic", fmt.Sprint(r)),
+		slog.String("stack", string(debug.Stack())),
+		slog.String("request_id", RequestID(ctx)),
+	)
+	*err = statusError(ctx, apierr.New(apierr.CodeInternal, "internal error"))
+}
+
+// DeadlineUnaryInterceptor ограничивает вызов дедлайном метода из cfg, если
+// клиент не передал более короткий.
+func DeadlineUnaryInterceptor(cfg config.GRPCConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := withMethodDeadline(ctx, cfg.Timeout(info.FullMethod))
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// DeadlineStreamInterceptor применяет только дедлайны, заданные для метода явно:
+// потоки обычно живут дольше unary-вызовов.
+func DeadlineStreamInterceptor(cfg config.GRPCConfig) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		timeout, ok := cfg.MethodTimeouts[info.FullMethod]
+		if !ok {
+			return handler(srv, ss)
+		}
+		ctx, cancel := withMethodDeadline(ss.Context(), timeout)
+		defer cancel()
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func withMethodDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// contextStream подменяет контекст потока для следующих перехватчиков и обработчика.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
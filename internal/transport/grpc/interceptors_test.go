@@ -0,0 +1,224 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/ratelimit"
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+)
+
+const testMethod = "/aggregator.AggregatorService/GetMax"
+
+func TestRequestIDUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+	captured := func(ctx context.Context) string {
+		var id string
+		_, err := RequestIDUnaryInterceptor(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+			id = RequestID(ctx)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		return id
+	}
+
+	t.Run("Taken from metadata", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDHeader, "req-1"))
+		assert.Equal(t, "req-1", captured(ctx))
+	})
+
+	t.Run("Generated when missing", func(t *testing.T) {
+		id := captured(context.Background())
+		assert.Len(t, id, 36)
+	})
+
+	t.Run("Generated when too long", func(t *testing.T) {
+		long := make([]byte, maxRequestIDLength+1)
+		for i := range long {
+			long[i] = 'a'
+		}
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDHeader, string(long)))
+		assert.Len(t, captured(ctx), 36)
+	})
+}
+
+func TestRecoveryUnaryInterceptor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	interceptor := RecoveryUnaryInterceptor(logger)
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod},
+		func(context.Context, any) (any, error) {
+			panic("boom")
+		})
+
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestDeadlineUnaryInterceptor(t *testing.T) {
+	cfg := config.GRPCConfig{
+		DefaultTimeout: time.Minute,
+		MethodTimeouts: map[string]time.Duration{testMethod: time.Second},
+	}
+	interceptor := DeadlineUnaryInterceptor(cfg)
+
+	remaining := func(ctx context.Context, method string) time.Duration {
+		var left time.Duration
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, _ any) (any, error) {
+				deadline, ok := ctx.Deadline()
+				require.True(t, ok)
+				left = time.Until(deadline)
+				return nil, nil
+			})
+		require.NoError(t, err)
+		return left
+	}
+
+	t.Run("Method timeout", func(t *testing.T) {
+		assert.LessOrEqual(t, remaining(context.Background(), testMethod), time.Second)
+	})
+
+	t.Run("Default timeout", func(t *testing.T) {
+		left := remaining(context.Background(), "/aggregator.AggregatorService/ListRevisions")
+		assert.Greater(t, left, time.Second)
+		assert.LessOrEqual(t, left, time.Minute)
+	})
+
+	t.Run("Shorter client deadline is kept", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		assert.LessOrEqual(t, remaining(ctx, testMethod), 100*time.Millisecond)
+	})
+}
+
+func TestAuthUnaryInterceptor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name":"reader","key":"r-key","scopes":["read"]}]`), 0o600))
+	authn, err := auth.New(context.Background(), config.AuthConfig{Enabled: true, APIKeysFile: path}, logger)
+	require.NoError(t, err)
+
+	interceptor := AuthUnaryInterceptor(authn, func(method string) auth.Scope {
+		if method == testMethod {
+			return auth.ScopeRead
+		}
+		return auth.ScopeAdmin
+	}, logger)
+
+	call := func(method string, md metadata.MD) (string, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		var subject string
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, _ any) (any, error) {
+			p, _ := auth.FromContext(ctx)
+			subject = p.Subject
+			return nil, nil
+		})
+		return subject, err
+	}
+
+	t.Run("Bearer api key", func(t *testing.T) {
+		subject, err := call(testMethod, metadata.Pairs("authorization", "Bearer r-key"))
+		require.NoError(t, err)
+		assert.Equal(t, "reader", subject)
+	})
+
+	t.Run("x-api-key", func(t *testing.T) {
+		_, err := call(testMethod, metadata.Pairs(APIKeyHeader, "r-key"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Missing credentials", func(t *testing.T) {
+		_, err := call(testMethod, metadata.MD{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Insufficient scope", func(t *testing.T) {
+		_, err := call("/aggregator.AdminService/StartReplay", metadata.Pairs(APIKeyHeader, "r-key"))
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestRateLimitUnaryInterceptor(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	limiter := ratelimit.New(config.RateLimitConfig{Enabled: true, RPS: 1, Burst: 1, PeriodRPS: 1, PeriodBurst: 1})
+	interceptor := RateLimitUnaryInterceptor(limiter, func(string, any) ratelimit.Class { return ratelimit.ClassDefault }, logger)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 5000}})
+	call := func() error {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, func(context.Context, any) (any, error) {
+			return nil, nil
+		})
+		return err
+	}
+
+	require.NoError(t, call())
+
+	err := call()
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	retry := detail[*errdetails.RetryInfo](t, st)
+	assert.Equal(t, time.Second, retry.GetRetryDelay().AsDuration())
+	assert.Equal(t, "RATE_LIMITED", detail[*errdetails.ErrorInfo](t, st).GetReason())
+}
+
+func TestErrorStatus(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-42")
+
+	t.Run("Domain error", func(t *testing.T) {
+		st := status.Convert(errorStatus(ctx, logger, "failed", fmt.Errorf("get: %w", repository.ErrNotFound)))
+		assert.Equal(t, codes.NotFound, st.Code())
+		info := detail[*errdetails.ErrorInfo](t, st)
+		assert.Equal(t, "RECORD_NOT_FOUND", info.GetReason())
+		assert.Equal(t, apierr.Domain, info.GetDomain())
+		assert.Equal(t, "req-42", detail[*errdetails.RequestInfo](t, st).GetRequestId())
+	})
+
+	t.Run("Internal error hides cause", func(t *testing.T) {
+		st := status.Convert(errorStatus(ctx, logger, "failed", errors.New("connection refused by 10.0.0.5")))
+		assert.Equal(t, codes.Internal, st.Code())
+		assert.Equal(t, "internal error", st.Message())
+	})
+
+	t.Run("Field violations", func(t *testing.T) {
+		st := status.Convert(statusError(ctx, apierr.InvalidArgument("uuid", "must be provided")))
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		violations := detail[*errdetails.BadRequest](t, st).GetFieldViolations()
+		require.Len(t, violations, 1)
+		assert.Equal(t, "uuid", violations[0].GetField())
+	})
+}
+
+// detail находит в статусе деталь типа T.
+func detail[T any](t *testing.T, st *status.Status) T {
+	t.Helper()
+	for _, d := range st.Details() {
+		if v, ok := d.(T); ok {
+			return v
+		}
+	}
+	var zero T
+	require.Failf(t, "detail not found", "%T in %v", zero, st.Details())
+	return zero
+}
@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/ratelimit"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RetryAfterHeader дублирует RetryInfo из деталей статуса для клиентов,
+// которые их не разбирают.
+const RetryAfterHeader = "retry-after"
+
+// RequestClass относит вызов к классу лимита по методу и запросу.
+type RequestClass func(fullMethod string, req any) ratelimit.Class
+
+// RateLimitUnaryInterceptor отклоняет вызовы сверх лимита клиента с
+// RESOURCE_EXHAUSTED и RetryInfo. Должен стоять после AuthUnaryInterceptor.
+// При limiter == nil вызовы не ограничиваются.
+func RateLimitUnaryInterceptor(limiter *ratelimit.Limiter, classify RequestClass, log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if limiter == nil {
+			return handler(ctx, req)
+		}
+		if err := throttle(ctx, limiter, classify(info.FullMethod, req), info.FullMethod, log); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor учитывает открытие потока как один запрос
+// класса ratelimit.ClassDefault.
+func RateLimitStreamInterceptor(limiter *ratelimit.Limiter, log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if limiter == nil {
+			return handler(srv, ss)
+		}
+		if err := throttle(ss.Context(), limiter, ratelimit.ClassDefault, info.FullMethod, log); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func throttle(ctx context.Context, limiter *ratelimit.Limiter, class ratelimit.Class, method string, log *slog.Logger) error {
+	var remote string
+	if p, ok := peer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+	}
+	client := ratelimit.ClientKey(ctx, remote)
+
+	ok, retryAfter := limiter.Allow(class, client)
+	if ok {
+		return nil
+	}
+
+	metrics.RateLimitThrottled.WithLabelValues("grpc", string(class)).Inc()
+	log.WarnContext(ctx, "rpc throttled",
+		slog.String("method", method),
+		slog.String("client", client),
+		slog.String("class", string(class)),
+		slog.Duration("retry_after", retryAfter),
+		slog.String("request_id", RequestID(ctx)),
+	)
+
+	seconds := max(1, int(math.Ceil(retryAfter.Seconds())))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterHeader, strconv.Itoa(seconds)))
+
+	return statusError(ctx, apierr.New(apierr.CodeRateLimited, "rate limit exceeded"),
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Duration(seconds) * time.Second)})
+}
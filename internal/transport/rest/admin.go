@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) GetConsumerGroupStatus(w http.ResponseWriter, r *http.Request) {
+	const op = "rest.GetConsumerGroupStatus"
+	log := h.log.With(slog.String("op", op))
+
+	st, err := h.admin.ConsumerGroupStatus(r.Context())
+	if err != nil {
+		respondError(w, r, log, "failed to describe consumer group",
+			apierr.Wrap(apierr.CodeUnavailable, "failed to describe consumer group", err))
+		return
+	}
+
+	respondJSON(w, http.StatusOK, st)
+}
+
+func (h *Handler) StartReplay(w http.ResponseWriter, r *http.Request) {
+	const op = "rest.StartReplay"
+	log := h.log.With(slog.String("op", op))
+
+	var req model.ReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Info("invalid replay request body", slog.Any("error", err))
+		respondProblem(w, r, apierr.New(apierr.CodeInvalidArgument, "invalid request body"))
+		return
+	}
+
+	job, err := h.admin.StartReplay(req)
+	if err != nil {
+		respondError(w, r, log, "failed to start replay", err)
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, job)
+}
+
+func (h *Handler) GetReplay(w http.ResponseWriter, r *http.Request) {
+	job, err := h.admin.Replay(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, r, h.log, "replay request failed", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, job)
+}
+
+func (h *Handler) CancelReplay(w http.ResponseWriter, r *http.Request) {
+	job, err := h.admin.CancelReplay(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, r, h.log, "replay request failed", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, job)
+}
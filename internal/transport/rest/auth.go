@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// APIKeyHeader — альтернатива заголовку Authorization для API-ключей.
+const APIKeyHeader = "X-API-Key"
+
+// AuthMiddleware пропускает запрос, только если клиент передал токен с областью
+// scope. При authn == nil аутентификация выключена и запрос не проверяется.
+func AuthMiddleware(authn *auth.Authenticator, scope auth.Scope, log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if authn == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := authn.Authorize(r.Context(), credentials(r), scope)
+			if err != nil {
+				log.WarnContext(r.Context(), "request rejected by auth",
+					slog.String("path", r.URL.Path),
+					slog.String("scope", string(scope)),
+					slog.Any("error", err),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+				e := apierr.From(err)
+				if e.Code == apierr.CodeUnauthenticated {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="aggregator"`)
+				}
+				respondProblem(w, r, e)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+		})
+	}
+}
+
+// credentials берёт токен из Authorization: Bearer или X-API-Key.
+func credentials(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); h != "" {
+		if scheme, token, ok := strings.Cut(h, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return r.Header.Get(APIKeyHeader)
+}
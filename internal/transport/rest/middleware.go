@@ -1,13 +1,104 @@
 package rest
 
 import (
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 	"time"
 
-	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 	"log/slog"
 )
 
+// TracingMiddleware начинает спан запроса, продолжая трассировку из заголовка
+// traceparent. Шаблон маршрута chi известен только после маршрутизации, поэтому
+// имя спана уточняется после обработки; otelhttp тоже переименовывает спан,
+// когда chi заполняет r.Pattern, поэтому formatter учитывает шаблон.
+func TracingMiddleware(tp trace.TracerProvider) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+
+			if pattern := chi.RouteContext(r.Context()).RoutePattern(); pattern != "" {
+				span := trace.SpanFromContext(r.Context())
+				span.SetName(r.Method + " " + pattern)
+				span.SetAttributes(semconv.HTTPRoute(pattern))
+			}
+		})
+
+		return otelhttp.NewHandler(named, "http",
+			otelhttp.WithTracerProvider(tp),
+			otelhttp.WithSpanNameFormatter(spanName),
+			otelhttp.WithFilter(func(r *http.Request) bool { return r.URL.Path != "/metrics" }),
+		)
+	}
+}
+
+func spanName(_ string, r *http.Request) string {
+	if pattern := chi.RouteContext(r.Context()).RoutePattern(); pattern != "" {
+		return r.Method + " " + pattern
+	}
+	return r.Method
+}
+
+// RealIPMiddleware подставляет в r.RemoteAddr адрес клиента из X-Forwarded-For
+// или X-Real-IP, но только если запрос пришёл от доверенного прокси: иначе
+// клиент мог бы выдать себя за любой адрес и обойти ограничение частоты.
+// В X-Forwarded-For клиентом считается правый адрес, не принадлежащий прокси.
+func RealIPMiddleware(trusted []netip.Prefix) func(next http.Handler) http.Handler {
+	isTrusted := func(addr netip.Addr) bool {
+		for _, p := range trusted {
+			if p.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(trusted) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, _, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host = r.RemoteAddr
+			}
+			if peer, err := netip.ParseAddr(host); err == nil && isTrusted(peer) {
+				if client, ok := forwardedClient(r.Header, isTrusted); ok {
+					r.RemoteAddr = client.String()
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func forwardedClient(h http.Header, isTrusted func(netip.Addr) bool) (netip.Addr, bool) {
+	var hops []string
+	for _, v := range h.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Дальше по цепочке адреса записаны неизвестно кем.
+			return netip.Addr{}, false
+		}
+		if !isTrusted(addr) || i == 0 {
+			return addr, true
+		}
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSpace(h.Get("X-Real-IP")))
+	return addr, err == nil
+}
+
 func AccessLogMiddleware(log *slog.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -17,7 +108,7 @@ func AccessLogMiddleware(log *slog.Logger) func(next http.Handler) http.Handler
 
 			next.ServeHTTP(ww, r)
 
-			log.Info("incoming request",
+			log.InfoContext(r.Context(), "incoming request",
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
 				slog.String("remote", r.RemoteAddr),
@@ -0,0 +1,82 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRealIPMiddleware(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "Untrusted peer headers are ignored",
+			remote:  "203.0.113.7:5000",
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.1", "X-Real-IP": "198.51.100.2"},
+			want:    "203.0.113.7:5000",
+		},
+		{
+			name:    "Rightmost untrusted hop",
+			remote:  "10.0.0.2:5000",
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.9, 10.0.0.5"},
+			want:    "203.0.113.9",
+		},
+		{
+			name:    "Real IP without forwarded chain",
+			remote:  "10.0.0.2:5000",
+			headers: map[string]string{"X-Real-IP": "203.0.113.9"},
+			want:    "203.0.113.9",
+		},
+		{
+			name:    "Garbage hop stops the chain",
+			remote:  "10.0.0.2:5000",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.9, not-an-ip"},
+			want:    "10.0.0.2:5000",
+		},
+		{
+			name:   "Trusted peer without headers",
+			remote: "10.0.0.2:5000",
+			want:   "10.0.0.2:5000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := RealIPMiddleware(trusted)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/max", nil)
+			req.RemoteAddr = tt.remote
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("No trusted proxies", func(t *testing.T) {
+		var got string
+		h := RealIPMiddleware(nil)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			got = r.RemoteAddr
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/max", nil)
+		req.RemoteAddr = "10.0.0.2:5000"
+		req.Header.Set("X-Forwarded-For", "198.51.100.1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, "10.0.0.2:5000", got)
+	})
+}
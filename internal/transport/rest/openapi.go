@@ -0,0 +1,172 @@
+package rest
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/go-chi/chi/v5"
+)
+
+// openAPIDocument — спецификация /api/v1. Она же отдаётся клиентам и проверяет
+// запросы, поэтому маршрут, не описанный в ней, не пройдёт валидацию.
+//
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// loadOpenAPI разбирает и проверяет встроенную спецификацию.
+func loadOpenAPI() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPIDocument)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func serveOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPIDocument)
+}
+
+// OpenAPIValidationMiddleware проверяет параметры и тело запроса по операции
+// спецификации, найденной по шаблону маршрута chi. Поэтому он должен стоять
+// внутри группы маршрутов (r.Group), где маршрут уже выбран.
+func OpenAPIValidationMiddleware(doc *openapi3.T, log *slog.Logger) func(next http.Handler) http.Handler {
+	options := &openapi3filter.Options{
+		// Учётные данные проверяет AuthMiddleware.
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		// Клиент получает все нарушения сразу, а не только первое.
+		MultiError: true,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, params, err := findRoute(doc, r)
+			if err != nil {
+				respondError(w, r, log, "route is missing from openapi spec", err)
+				return
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: params,
+				Route:      route,
+				Options:    options,
+			}
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				respondProblem(w, r, validationError(err))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func findRoute(doc *openapi3.T, r *http.Request) (*routers.Route, map[string]string, error) {
+	rctx := chi.RouteContext(r.Context())
+	pattern := rctx.RoutePattern()
+
+	item := doc.Paths.Find(pattern)
+	if item == nil {
+		return nil, nil, fmt.Errorf("path %s is not described", pattern)
+	}
+	op := item.GetOperation(r.Method)
+	if op == nil {
+		return nil, nil, fmt.Errorf("operation %s %s is not described", r.Method, pattern)
+	}
+
+	params := make(map[string]string, len(rctx.URLParams.Keys))
+	for i, key := range rctx.URLParams.Keys {
+		params[key] = rctx.URLParams.Values[i]
+	}
+
+	return &routers.Route{
+		Spec:      doc,
+		Path:      pattern,
+		PathItem:  item,
+		Method:    r.Method,
+		Operation: op,
+	}, params, nil
+}
+
+// validationError переводит ошибки kin-openapi в нарушения по полям:
+// имя параметра или путь в теле запроса и причину без дампа схемы.
+func validationError(err error) *apierr.Error {
+	return apierr.New(apierr.CodeInvalidArgument, "request does not match the api specification", violations(err)...)
+}
+
+func violations(err error) []apierr.FieldViolation {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var out []apierr.FieldViolation
+		for _, err := range e {
+			out = append(out, violations(err)...)
+		}
+		return out
+	case *openapi3filter.RequestError:
+		switch {
+		case e.Parameter != nil:
+			return []apierr.FieldViolation{{Field: e.Parameter.Name, Description: reason(e)}}
+		case e.RequestBody != nil:
+			return bodyViolations(e)
+		default:
+			return []apierr.FieldViolation{{Field: "request", Description: reason(e)}}
+		}
+	default:
+		return []apierr.FieldViolation{{Field: "request", Description: "invalid request"}}
+	}
+}
+
+// bodyViolations раскладывает ошибки схемы тела по путям полей (body.offsets.0).
+func bodyViolations(e *openapi3filter.RequestError) []apierr.FieldViolation {
+	var errs []error
+	if me, ok := e.Err.(openapi3.MultiError); ok {
+		errs = me
+	} else {
+		errs = []error{e.Err}
+	}
+
+	var out []apierr.FieldViolation
+	for _, err := range errs {
+		se, ok := err.(*openapi3.SchemaError)
+		if !ok {
+			out = append(out, apierr.FieldViolation{Field: "body", Description: reason(e)})
+			continue
+		}
+		field := strings.Join(append([]string{"body"}, se.JSONPointer()...), ".")
+		out = append(out, apierr.FieldViolation{Field: field, Description: se.Reason})
+	}
+	return out
+}
+
+func reason(e *openapi3filter.RequestError) string {
+	switch err := e.Err.(type) {
+	case *openapi3.SchemaError:
+		return err.Reason
+	case openapi3.MultiError:
+		reasons := make([]string, 0, len(err))
+		for _, err := range err {
+			if se, ok := err.(*openapi3.SchemaError); ok {
+				reasons = append(reasons, se.Reason)
+			} else {
+				reasons = append(reasons, err.Error())
+			}
+		}
+		return strings.Join(reasons, "; ")
+	case nil:
+		return e.Reason
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+func TestOpenAPIValidationMiddleware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	doc, err := loadOpenAPI()
+	require.NoError(t, err)
+
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(OpenAPIValidationMiddleware(doc, logger))
+			r.Get("/records", ok)
+			r.Get("/records/{uuid}", ok)
+			r.Post("/admin/replays", ok)
+			r.Get("/undocumented", ok)
+		})
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+		fields []string
+	}{
+		{"Valid period", http.MethodGet, "/api/v1/records?from=2025-01-01T00:00:00Z&to=2025-01-02T00:00:00Z&limit=10", "", http.StatusNoContent, nil},
+		{"Missing from", http.MethodGet, "/api/v1/records?to=2025-01-02T00:00:00Z", "", http.StatusBadRequest, []string{"from"}},
+		{"Limit out of range", http.MethodGet, "/api/v1/records?from=2025-01-01T00:00:00Z&to=2025-01-02T00:00:00Z&limit=0", "", http.StatusBadRequest, []string{"limit"}},
+		{"All violations", http.MethodGet, "/api/v1/records?from=x&limit=5000", "", http.StatusBadRequest, []string{"from", "to", "limit"}},
+		{"Bad date-time", http.MethodGet, "/api/v1/records/abc?as_of=yesterday", "", http.StatusBadRequest, []string{"as_of"}},
+		{"Unknown body field", http.MethodPost, "/api/v1/admin/replays", `{"from":"2025-01-01T00:00:00Z"}`, http.StatusBadRequest, []string{"body"}},
+		{"Invalid body field", http.MethodPost, "/api/v1/admin/replays", `{"offsets":{"0":-1}}`, http.StatusBadRequest, []string{"body.offsets.0"}},
+		{"Valid body", http.MethodPost, "/api/v1/admin/replays", `{"offsets":{"0":5}}`, http.StatusNoContent, nil},
+		{"Route missing from spec", http.MethodGet, "/api/v1/undocumented", "", http.StatusInternalServerError, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.status, rec.Code)
+			if tc.status == http.StatusNoContent {
+				return
+			}
+			p := decodeProblem(t, rec)
+			var fields []string
+			for _, f := range p.Errors {
+				fields = append(fields, f.Field)
+			}
+			assert.ElementsMatch(t, tc.fields, fields)
+		})
+	}
+}
+
+func TestPageToken(t *testing.T) {
+	cursor := model.RecordCursor{Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 123, time.UTC), UUID: "id-1"}
+
+	decoded, err := decodePageToken(encodePageToken(cursor))
+	require.NoError(t, err)
+	assert.Equal(t, cursor, *decoded)
+
+	_, err = decodePageToken("not base64!")
+	assert.Error(t, err)
+}
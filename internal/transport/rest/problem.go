@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+)
+
+// ProblemContentType — тип ответов с ошибкой (RFC 7807).
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix вместе с кодом ошибки образует поле type.
+const problemTypePrefix = "urn:aggregator-service:problem:"
+
+type problem struct {
+	Type      string                  `json:"type"`
+	Title     string                  `json:"title"`
+	Status    int                     `json:"status"`
+	Detail    string                  `json:"detail,omitempty"`
+	Instance  string                  `json:"instance,omitempty"`
+	Code      apierr.Code             `json:"code"`
+	RequestID string                  `json:"request_id,omitempty"`
+	Errors    []apierr.FieldViolation `json:"errors,omitempty"`
+}
+
+// respondProblem отдаёт ошибку клиенту в формате problem+json.
+func respondProblem(w http.ResponseWriter, r *http.Request, e *apierr.Error) {
+	status := e.Code.HTTPStatus()
+	p := problem{
+		Type:      problemTypePrefix + string(e.Code),
+		Title:     statusText(status),
+		Status:    status,
+		Detail:    e.Message,
+		Instance:  r.URL.Path,
+		Code:      e.Code,
+		RequestID: middleware.GetReqID(r.Context()),
+		Errors:    e.Fields,
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.Default().Error("failed to encode problem response", slog.Any("error", err))
+	}
+}
+
+// respondError сопоставляет ошибку сервиса с кодом и отдаёт её клиенту.
+// Внутренние ошибки логируются с исходной причиной под сообщением msg,
+// прерванные запросы — с предупреждением.
+func respondError(w http.ResponseWriter, r *http.Request, log *slog.Logger, msg string, err error) {
+	e := apierr.From(err)
+	switch {
+	case e.Internal():
+		log.ErrorContext(r.Context(), msg, slog.Any("error", err))
+	case e.Interrupted():
+		log.WarnContext(r.Context(), msg, slog.String("code", string(e.Code)), slog.Any("error", err))
+	default:
+		log.InfoContext(r.Context(), msg, slog.String("code", string(e.Code)))
+	}
+	respondProblem(w, r, e)
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	respondProblem(w, r, apierr.New(apierr.CodeNotFound, "route not found"))
+}
+
+// statusText дополняет http.StatusText нестандартным статусом 499.
+func statusText(status int) string {
+	if status == apierr.StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/repository"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/service/mocks"
+)
+
+func TestProblemResponses(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := &mocks.MockMaxValueRepository{
+		GetMaxByIDFunc: func(context.Context, string) (*model.MaxValue, error) {
+			return nil, repository.ErrNotFound
+		},
+		GetRecordFunc: func(context.Context, string) (*model.Record, error) {
+			return nil, errors.New("connection refused by 10.0.0.5")
+		},
+	}
+	reg := prometheus.NewRegistry()
+	router := New(service.New(logger, repo), nil, logger, metrics.NewHTTP(reg), reg, noop.NewTracerProvider(), nil, true, nil, nil)
+
+	get := func(target string) (*httptest.ResponseRecorder, problem) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("X-Request-Id", "req-42")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec, decodeProblem(t, rec)
+	}
+
+	t.Run("Not found", func(t *testing.T) {
+		rec, p := get("/max?uuid=a1")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, apierr.CodeRecordNotFound, p.Code)
+		assert.Equal(t, "urn:aggregator-service:problem:record_not_found", p.Type)
+		assert.Equal(t, "/max", p.Instance)
+		assert.Equal(t, "req-42", p.RequestID)
+	})
+
+	t.Run("Internal error hides cause", func(t *testing.T) {
+		rec, p := get("/api/v1/records/a1")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, apierr.CodeInternal, p.Code)
+		assert.NotContains(t, rec.Body.String(), "10.0.0.5")
+	})
+
+	t.Run("Legacy parameter error", func(t *testing.T) {
+		rec, p := get("/max?uuid=a1&as_of=yesterday")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Len(t, p.Errors, 1)
+		assert.Equal(t, "as_of", p.Errors[0].Field)
+	})
+
+	t.Run("Reversed period", func(t *testing.T) {
+		rec, p := get("/api/v1/records?from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Len(t, p.Errors, 1)
+		assert.Equal(t, "to", p.Errors[0].Field)
+	})
+
+	t.Run("Unknown route", func(t *testing.T) {
+		rec, p := get("/nope")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, apierr.CodeNotFound, p.Code)
+	})
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) problem {
+	t.Helper()
+	require.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+	var p problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+	assert.Equal(t, rec.Code, p.Status)
+	return p
+}
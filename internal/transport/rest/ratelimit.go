@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/ratelimit"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RateLimitMiddleware отклоняет запросы сверх лимита клиента с 429 и Retry-After.
+// Должен стоять после AuthMiddleware, чтобы считать запросы по ключу, а не по IP.
+// При limiter == nil запросы не ограничиваются.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			class := requestClass(r)
+			client := ratelimit.ClientKey(r.Context(), r.RemoteAddr)
+
+			ok, retryAfter := limiter.Allow(class, client)
+			if !ok {
+				metrics.RateLimitThrottled.WithLabelValues("http", string(class)).Inc()
+				log.WarnContext(r.Context(), "request throttled",
+					slog.String("client", client),
+					slog.String("class", string(class)),
+					slog.Duration("retry_after", retryAfter),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				)
+				w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+				respondProblem(w, r, apierr.New(apierr.CodeRateLimited, "too many requests"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestClass относит запросы по периоду к дорогим: GET /max без uuid,
+// но с периодом, и выборки /api/v1/records и /api/v1/series.
+func requestClass(r *http.Request) ratelimit.Class {
+	q := r.URL.Query()
+	switch r.URL.Path {
+	case "/max":
+		if q.Get("uuid") == "" && (q.Get("from") != "" || q.Get("to") != "") {
+			return ratelimit.ClassPeriod
+		}
+	case "/api/v1/records", "/api/v1/series":
+		return ratelimit.ClassPeriod
+	}
+	return ratelimit.ClassDefault
+}
+
+// retryAfterSeconds округляет вверх: Retry-After принимает только целые секунды.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(d.Seconds()))))
+}
@@ -0,0 +1,162 @@
+package rest
+
+import (
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultPageSize — limit по умолчанию для /api/v1/records.
+const defaultPageSize = 100
+
+// Обработчики /api/v1. Формат параметров уже проверен OpenAPIValidationMiddleware,
+// здесь остаются только проверки, которые спецификация выразить не может.
+
+type listResponse[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+type seriesResponse struct {
+	UUID   string                   `json:"uuid"`
+	Points []model.MaxValueRevision `json:"points"`
+}
+
+func (h *Handler) GetRecord(w http.ResponseWriter, r *http.Request) {
+	const op = "rest.GetRecord"
+	log := h.log.With(slog.String("op", op))
+
+	uuid := chi.URLParam(r, "uuid")
+
+	var (
+		rec *model.Record
+		err error
+	)
+	if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+		ts, _ := time.Parse(time.RFC3339, asOf)
+		rec, err = h.service.GetRecordAsOf(r.Context(), uuid, ts)
+	} else {
+		rec, err = h.service.GetRecord(r.Context(), uuid)
+	}
+	if err != nil {
+		respondError(w, r, log.With(slog.String("uuid", uuid)), "failed to get record", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rec)
+}
+
+func (h *Handler) ListRecords(w http.ResponseWriter, r *http.Request) {
+	const op = "rest.ListRecords"
+	log := h.log.With(slog.String("op", op))
+
+	q := r.URL.Query()
+	from, to, ok := period(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultPageSize
+	if s := q.Get("limit"); s != "" {
+		limit, _ = strconv.Atoi(s)
+	}
+
+	query := model.RecordQuery{From: from, To: to, Limit: limit}
+	if token := q.Get("page_token"); token != "" {
+		cursor, err := decodePageToken(token)
+		if err != nil {
+			respondProblem(w, r, apierr.InvalidArgument("page_token", "must be a next_page_token from a previous page"))
+			return
+		}
+		query.After = cursor
+	}
+
+	records, next, err := h.service.ListRecords(r.Context(), query)
+	if err != nil {
+		respondError(w, r, log, "failed to list records", err)
+		return
+	}
+
+	resp := listResponse[model.Record]{Items: records}
+	if next != nil {
+		resp.NextPageToken = encodePageToken(*next)
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) ListRecordRevisions(w http.ResponseWriter, r *http.Request) {
+	const op = "rest.ListRecordRevisions"
+	log := h.log.With(slog.String("op", op))
+
+	uuid := chi.URLParam(r, "uuid")
+
+	list, err := h.service.ListRevisions(r.Context(), uuid)
+	if err != nil {
+		respondError(w, r, log.With(slog.String("uuid", uuid)), "failed to list revisions", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, listResponse[model.MaxValueRevision]{Items: list})
+}
+
+func (h *Handler) GetSeries(w http.ResponseWriter, r *http.Request) {
+	const op = "rest.GetSeries"
+	log := h.log.With(slog.String("op", op))
+
+	q := r.URL.Query()
+	from, to, ok := period(w, r)
+	if !ok {
+		return
+	}
+	uuid := q.Get("uuid")
+
+	points, err := h.service.ListSeries(r.Context(), uuid, from, to)
+	if err != nil {
+		respondError(w, r, log, "failed to get series", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, seriesResponse{UUID: uuid, Points: points})
+}
+
+// period разбирает границы периода; формат проверен по спецификации,
+// а порядок границ — нет.
+func period(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	q := r.URL.Query()
+	from, _ := time.Parse(time.RFC3339, q.Get("from"))
+	to, _ := time.Parse(time.RFC3339, q.Get("to"))
+	if to.Before(from) {
+		respondProblem(w, r, apierr.InvalidArgument("to", "must not be before 'from'"))
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// encodePageToken кодирует курсор как ts и uuid последней записи страницы.
+func encodePageToken(c model.RecordCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.Timestamp.UTC().Format(time.RFC3339Nano) + "|" + c.UUID))
+}
+
+func decodePageToken(token string) (*model.RecordCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	tsStr, uuid, ok := strings.Cut(string(raw), "|")
+	if !ok || uuid == "" {
+		return nil, errors.New("malformed page token")
+	}
+	ts, err := time.Parse(time.RFC3339Nano, tsStr)
+	if err != nil {
+		return nil, err
+	}
+	return &model.RecordCursor{Timestamp: ts, UUID: uuid}, nil
+}
@@ -2,36 +2,111 @@ package rest
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"time"
 
+	"github.com/Pavel26ru/aggregator-service/internal/apierr"
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
 	"github.com/Pavel26ru/aggregator-service/internal/metrics"
-	"github.com/Pavel26ru/aggregator-service/internal/repository"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+	"github.com/Pavel26ru/aggregator-service/internal/ratelimit"
 	"github.com/Pavel26ru/aggregator-service/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Handler struct {
 	service *service.Service
+	admin   *service.Admin
 	log     *slog.Logger
 }
 
-func New(s *service.Service, log *slog.Logger) *chi.Mux {
+// New собирает роутер. Если authn не nil, каждый маршрут требует токен с нужной
+// областью доступа; /metrics остаётся открытым только при publicMetrics.
+// /metrics отдаёт метрики из gatherer; limiter ограничивает все маршруты API, кроме него.
+// Адрес клиента из заголовков прокси принимается только от trustedProxies.
+// Маршруты /api/v1 дополнительно проверяются по встроенной спецификации OpenAPI.
+func New(
+	s *service.Service,
+	a *service.Admin,
+	log *slog.Logger,
+	m *metrics.HTTP,
+	gatherer prometheus.Gatherer,
+	tp trace.TracerProvider,
+	authn *auth.Authenticator,
+	publicMetrics bool,
+	limiter *ratelimit.Limiter,
+	trustedProxies []netip.Prefix,
+) *chi.Mux {
+	doc, err := loadOpenAPI()
+	if err != nil {
+		// Спецификация встроена в бинарник, ошибка в ней — ошибка сборки.
+		panic(fmt.Sprintf("rest: invalid openapi spec: %v", err))
+	}
+
 	r := chi.NewRouter()
-	h := &Handler{service: s, log: log}
+	r.NotFound(notFound)
+	h := &Handler{service: s, admin: a, log: log}
 
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(RealIPMiddleware(trustedProxies))
+	r.Use(TracingMiddleware(tp))
 	r.Use(middleware.Recoverer)
 	r.Use(AccessLogMiddleware(log))
-	r.Use(metrics.Middleware)
+	r.Use(m.Middleware)
 
-	r.Handle("/metrics", promhttp.Handler())
-	r.Get("/max", h.GetMax)
+	r.Group(func(r chi.Router) {
+		if !publicMetrics {
+			r.Use(AuthMiddleware(authn, auth.ScopeAdmin, log))
+		}
+		r.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(AuthMiddleware(authn, auth.ScopeRead, log))
+		r.Use(RateLimitMiddleware(limiter, log))
+		r.Get("/max", h.GetMax)
+		r.Get("/max/{uuid}/revisions", h.ListRevisions)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(AuthMiddleware(authn, auth.ScopeAdmin, log))
+		r.Use(RateLimitMiddleware(limiter, log))
+		r.Get("/admin/kafka/group", h.GetConsumerGroupStatus)
+		r.Get("/admin/replays/{id}", h.GetReplay)
+		r.Post("/admin/replays", h.StartReplay)
+		r.Delete("/admin/replays/{id}", h.CancelReplay)
+	})
+
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/openapi.json", serveOpenAPI)
+
+		r.Group(func(r chi.Router) {
+			r.Use(AuthMiddleware(authn, auth.ScopeRead, log))
+			r.Use(RateLimitMiddleware(limiter, log))
+			r.Use(OpenAPIValidationMiddleware(doc, log))
+			r.Get("/records", h.ListRecords)
+			r.Get("/records/{uuid}", h.GetRecord)
+			r.Get("/records/{uuid}/revisions", h.ListRecordRevisions)
+			r.Get("/series", h.GetSeries)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(AuthMiddleware(authn, auth.ScopeAdmin, log))
+			r.Use(RateLimitMiddleware(limiter, log))
+			r.Use(OpenAPIValidationMiddleware(doc, log))
+			r.Get("/admin/kafka/group", h.GetConsumerGroupStatus)
+			r.Get("/admin/replays/{id}", h.GetReplay)
+			r.Post("/admin/replays", h.StartReplay)
+			r.Delete("/admin/replays/{id}", h.CancelReplay)
+		})
+	})
 
 	return r
 }
@@ -43,18 +118,27 @@ func (h *Handler) GetMax(w http.ResponseWriter, r *http.Request) {
 	uuid := r.URL.Query().Get("uuid")
 	fromStr := r.URL.Query().Get("from")
 	toStr := r.URL.Query().Get("to")
+	asOfStr := r.URL.Query().Get("as_of")
 
 	// По ID
 	if uuid != "" {
-		rec, err := h.service.GetMaxByID(r.Context(), uuid)
-		if err != nil {
-			if errors.Is(err, repository.ErrNotFound) {
-				log.Info("record not found", slog.String("uuid", uuid))
-				http.Error(w, "record not found", http.StatusNotFound)
+		var (
+			rec *model.MaxValue
+			err error
+		)
+		if asOfStr != "" {
+			asOf, parseErr := time.Parse(time.RFC3339, asOfStr)
+			if parseErr != nil {
+				log.Info("invalid 'as_of' timestamp format", slog.Any("error", parseErr))
+				respondProblem(w, r, apierr.InvalidArgument("as_of", "must be an RFC3339 timestamp"))
 				return
 			}
-			log.Error("failed to get record by id", slog.Any("error", err))
-			http.Error(w, "internal server error", http.StatusInternalServerError)
+			rec, err = h.service.GetMaxAsOf(r.Context(), uuid, asOf)
+		} else {
+			rec, err = h.service.GetMaxByID(r.Context(), uuid)
+		}
+		if err != nil {
+			respondError(w, r, log.With(slog.String("uuid", uuid)), "failed to get record by id", err)
 			return
 		}
 		respondJSON(w, http.StatusOK, rec)
@@ -65,22 +149,21 @@ func (h *Handler) GetMax(w http.ResponseWriter, r *http.Request) {
 	if fromStr != "" && toStr != "" {
 		from, err := time.Parse(time.RFC3339, fromStr)
 		if err != nil {
-			log.Error("invalid 'from' timestamp format", slog.Any("error", err))
-			http.Error(w, "invalid 'from' timestamp format", http.StatusBadRequest)
+			log.Info("invalid 'from' timestamp format", slog.Any("error", err))
+			respondProblem(w, r, apierr.InvalidArgument("from", "must be an RFC3339 timestamp"))
 			return
 		}
 
 		to, err := time.Parse(time.RFC3339, toStr)
 		if err != nil {
-			log.Error("invalid 'to' timestamp format", slog.Any("error", err))
-			http.Error(w, "invalid 'to' timestamp format", http.StatusBadRequest)
+			log.Info("invalid 'to' timestamp format", slog.Any("error", err))
+			respondProblem(w, r, apierr.InvalidArgument("to", "must be an RFC3339 timestamp"))
 			return
 		}
 
 		list, err := h.service.GetMaxByPeriod(r.Context(), from, to)
 		if err != nil {
-			log.Error("failed to get records by period", slog.Any("error", err))
-			http.Error(w, "internal server error", http.StatusInternalServerError)
+			respondError(w, r, log, "failed to get records by period", err)
 			return
 		}
 
@@ -88,7 +171,22 @@ func (h *Handler) GetMax(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	http.Error(w, "bad request: either uuid or a time period must be provided", http.StatusBadRequest)
+	respondProblem(w, r, apierr.New(apierr.CodeInvalidArgument, "either uuid or a time period must be provided"))
+}
+
+func (h *Handler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	const op = "rest.ListRevisions"
+	log := h.log.With(slog.String("op", op))
+
+	uuid := chi.URLParam(r, "uuid")
+
+	list, err := h.service.ListRevisions(r.Context(), uuid)
+	if err != nil {
+		respondError(w, r, log.With(slog.String("uuid", uuid)), "failed to list revisions", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, list)
 }
 
 func respondJSON(w http.ResponseWriter, status int, payload interface{}) {
@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/Pavel26ru/aggregator-service/internal/auth"
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/metrics"
+	"github.com/Pavel26ru/aggregator-service/internal/service"
+	"github.com/Pavel26ru/aggregator-service/internal/service/mocks"
+)
+
+func TestRouter_ReplayRequiresAdmin(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"name":"loader","key":"i-key","scopes":["ingest"]}]`), 0o600))
+	authn, err := auth.New(context.Background(), config.AuthConfig{Enabled: true, APIKeysFile: path}, logger)
+	require.NoError(t, err)
+
+	reg := prometheus.NewRegistry()
+	router := New(service.New(logger, &mocks.MockMaxValueRepository{}), nil, logger, metrics.NewHTTP(reg), reg, noop.NewTracerProvider(), authn, true, nil, nil)
+
+	requests := []struct{ method, target string }{
+		{http.MethodPost, "/admin/replays"},
+		{http.MethodGet, "/admin/replays/r1"},
+		{http.MethodDelete, "/admin/replays/r1"},
+		{http.MethodPost, "/api/v1/admin/replays"},
+		{http.MethodGet, "/api/v1/admin/replays/r1"},
+		{http.MethodDelete, "/api/v1/admin/replays/r1"},
+	}
+	for _, r := range requests {
+		t.Run(r.method+" "+r.target, func(t *testing.T) {
+			req := httptest.NewRequest(r.method, r.target, strings.NewReader(`{}`))
+			req.Header.Set("Authorization", "Bearer i-key")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+// Reason — стабильный код причины отклонения записи, используется в метриках.
+type Reason string
+
+const (
+	ReasonMissingUUID     Reason = "missing_uuid"
+	ReasonInvalidUUID     Reason = "invalid_uuid"
+	ReasonMissingTs       Reason = "missing_timestamp"
+	ReasonTsTooOld        Reason = "timestamp_too_old"
+	ReasonTsInFuture      Reason = "timestamp_in_future"
+	ReasonEmptyValues     Reason = "empty_values"
+	ReasonTooManyValues   Reason = "too_many_values"
+	ReasonUnknownRequired Reason = "unknown_required_field"
+)
+
+type Error struct {
+	Reason Reason
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, e.Msg)
+}
+
+type Validator struct {
+	cfg config.ValidationConfig
+
+	requireUUID   bool
+	requireTs     bool
+	requireValues bool
+
+	now func() time.Time
+}
+
+func New(cfg config.ValidationConfig) (*Validator, error) {
+	v := &Validator{cfg: cfg, now: time.Now}
+
+	for _, f := range cfg.RequiredFields {
+		switch f {
+		case "uuid":
+			v.requireUUID = true
+		case "timestamp":
+			v.requireTs = true
+		case "value":
+			v.requireValues = true
+		default:
+			return nil, &Error{Reason: ReasonUnknownRequired, Msg: f}
+		}
+	}
+
+	return v, nil
+}
+
+// Validate возвращает *Error с причиной отклонения или nil, если запись корректна.
+func (v *Validator) Validate(rec model.ValueRecord) error {
+	if rec.UUID == "" {
+		if v.requireUUID {
+			return &Error{Reason: ReasonMissingUUID, Msg: "uuid is empty"}
+		}
+	} else if v.cfg.UUIDFormat {
+		if _, err := uuid.Parse(rec.UUID); err != nil {
+			return &Error{Reason: ReasonInvalidUUID, Msg: err.Error()}
+		}
+	}
+
+	if rec.Timestamp.IsZero() {
+		if v.requireTs {
+			return &Error{Reason: ReasonMissingTs, Msg: "timestamp is zero"}
+		}
+	} else {
+		now := v.now()
+		if v.cfg.MaxPast > 0 && rec.Timestamp.Before(now.Add(-v.cfg.MaxPast)) {
+			return &Error{Reason: ReasonTsTooOld, Msg: fmt.Sprintf("timestamp %s is older than %s", rec.Timestamp, v.cfg.MaxPast)}
+		}
+		if v.cfg.MaxFuture > 0 && rec.Timestamp.After(now.Add(v.cfg.MaxFuture)) {
+			return &Error{Reason: ReasonTsInFuture, Msg: fmt.Sprintf("timestamp %s is more than %s ahead", rec.Timestamp, v.cfg.MaxFuture)}
+		}
+	}
+
+	if len(rec.Value) == 0 && v.requireValues {
+		return &Error{Reason: ReasonEmptyValues, Msg: "value is empty"}
+	}
+	if v.cfg.MaxValues > 0 && len(rec.Value) > v.cfg.MaxValues {
+		return &Error{Reason: ReasonTooManyValues, Msg: fmt.Sprintf("value has %d elements, max %d", len(rec.Value), v.cfg.MaxValues)}
+	}
+
+	return nil
+}
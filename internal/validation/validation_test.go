@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Pavel26ru/aggregator-service/internal/config"
+	"github.com/Pavel26ru/aggregator-service/internal/model"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	validUUID := "a1b2c3d4-e5f6-47b8-89d0-e1f2a3b4c5d6"
+
+	v, err := New(config.ValidationConfig{
+		RequiredFields: []string{"uuid", "timestamp", "value"},
+		UUIDFormat:     true,
+		MaxValues:      3,
+		MaxPast:        time.Hour,
+		MaxFuture:      time.Minute,
+	})
+	require.NoError(t, err)
+	v.now = func() time.Time { return now }
+
+	tests := []struct {
+		name   string
+		rec    model.ValueRecord
+		reason Reason
+	}{
+		{"Valid", model.ValueRecord{UUID: validUUID, Timestamp: now, Value: []int64{1}}, ""},
+		{"Missing uuid", model.ValueRecord{Timestamp: now, Value: []int64{1}}, ReasonMissingUUID},
+		{"Invalid uuid", model.ValueRecord{UUID: "not-a-uuid", Timestamp: now, Value: []int64{1}}, ReasonInvalidUUID},
+		{"Missing timestamp", model.ValueRecord{UUID: validUUID, Value: []int64{1}}, ReasonMissingTs},
+		{"Too old", model.ValueRecord{UUID: validUUID, Timestamp: now.Add(-2 * time.Hour), Value: []int64{1}}, ReasonTsTooOld},
+		{"In future", model.ValueRecord{UUID: validUUID, Timestamp: now.Add(2 * time.Minute), Value: []int64{1}}, ReasonTsInFuture},
+		{"Empty values", model.ValueRecord{UUID: validUUID, Timestamp: now}, ReasonEmptyValues},
+		{"Too many values", model.ValueRecord{UUID: validUUID, Timestamp: now, Value: []int64{1, 2, 3, 4}}, ReasonTooManyValues},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(tt.rec)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var verr *Error
+			require.True(t, errors.As(err, &verr))
+			assert.Equal(t, tt.reason, verr.Reason)
+		})
+	}
+}
+
+func TestValidator_OptionalFields(t *testing.T) {
+	v, err := New(config.ValidationConfig{})
+	require.NoError(t, err)
+
+	assert.NoError(t, v.Validate(model.ValueRecord{UUID: strings.Repeat("x", 5)}))
+}
+
+func TestNew_UnknownRequiredField(t *testing.T) {
+	_, err := New(config.ValidationConfig{RequiredFields: []string{"payload"}})
+
+	var verr *Error
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, ReasonUnknownRequired, verr.Reason)
+}
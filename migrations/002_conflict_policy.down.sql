@@ -0,0 +1 @@
+ALTER TABLE max_values DROP COLUMN IF EXISTS version;
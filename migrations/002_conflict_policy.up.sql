@@ -0,0 +1 @@
+ALTER TABLE max_values ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
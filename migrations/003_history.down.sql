@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS max_values_history_uuid_ts_idx;
+
+DROP TABLE IF EXISTS max_values_history;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS max_values_history (
+    uuid        VARCHAR(36) NOT NULL,
+    version     BIGINT      NOT NULL,
+    ts          TIMESTAMP   NOT NULL,
+    max_value   BIGINT      NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (uuid, version)
+    );
+
+INSERT INTO max_values_history (uuid, version, ts, max_value)
+SELECT uuid, version, ts, max_value FROM max_values
+ON CONFLICT (uuid, version) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS max_values_history_uuid_ts_idx ON max_values_history (uuid, ts);
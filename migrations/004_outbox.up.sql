@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS outbox (
+    id               BIGSERIAL   PRIMARY KEY,
+    topic            TEXT        NOT NULL,
+    uuid             VARCHAR(36) NOT NULL,
+    version          BIGINT      NOT NULL,
+    ts               TIMESTAMP   NOT NULL,
+    max_value        BIGINT      NOT NULL,
+    source_topic     TEXT,
+    source_partition INTEGER,
+    source_offset    BIGINT,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+    );
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS aggregates;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS aggregates (
+    uuid       VARCHAR(36) NOT NULL,
+    aggregator TEXT        NOT NULL,
+    tag        TEXT        NOT NULL,
+    ts         TIMESTAMP   NOT NULL,
+    value      BIGINT      NOT NULL,
+    PRIMARY KEY (uuid, aggregator, tag)
+    );
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS max_values_history_source_idx;
+
+ALTER TABLE max_values_history
+    DROP COLUMN IF EXISTS source_topic,
+    DROP COLUMN IF EXISTS source_partition,
+    DROP COLUMN IF EXISTS source_offset;
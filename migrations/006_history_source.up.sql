@@ -0,0 +1,7 @@
+ALTER TABLE max_values_history
+    ADD COLUMN IF NOT EXISTS source_topic     TEXT,
+    ADD COLUMN IF NOT EXISTS source_partition INTEGER,
+    ADD COLUMN IF NOT EXISTS source_offset    BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS max_values_history_source_idx
+    ON max_values_history (source_topic, source_partition, source_offset, uuid, ts);